@@ -0,0 +1,66 @@
+/*
+Copyright 2024 IsmailAbdelkefi.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.reason"
+// +kubebuilder:printcolumn:name="Handled Resources",type="integer",JSONPath=".status.handledResources"
+// +kubebuilder:printcolumn:name="Next Operation",type="string",JSONPath=".status.nextOperation"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Dry Run",type="boolean",JSONPath=".status.dryRun",priority=1
+
+// ClusterKronosApp is the Schema for the clusterkronosapps API. It applies a
+// KronosApp schedule cluster-wide, typically to every namespace selected by
+// the namespaceSelector of its included objects. Workloads also claimed by a
+// namespaced KronosApp are left to that KronosApp.
+type ClusterKronosApp struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KronosAppSpec   `json:"spec,omitempty"`
+	Status KronosAppStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterKronosAppList contains a list of ClusterKronosApp
+type ClusterKronosAppList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterKronosApp `json:"items"`
+}
+
+var _ KronosAppObject = &ClusterKronosApp{}
+
+func (k *ClusterKronosApp) GetSpec() *KronosAppSpec {
+	return &k.Spec
+}
+
+func (k *ClusterKronosApp) GetStatus() *KronosAppStatus {
+	return &k.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterKronosApp{}, &ClusterKronosAppList{})
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 IsmailAbdelkefi.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var clusterkronosapplog = logf.Log.WithName("clusterkronosapp-resource")
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (r *ClusterKronosApp) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetAPIReader()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-core-wecraft-tn-v1alpha1-clusterkronosapp,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.wecraft.tn,resources=clusterkronosapps,verbs=create;update,versions=v1alpha1,name=vclusterkronosapp.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ClusterKronosApp{}
+
+// asKronosApp wraps the spec so that the KronosApp validation applies as is.
+func (r *ClusterKronosApp) asKronosApp() *KronosApp {
+	return &KronosApp{ObjectMeta: r.ObjectMeta, Spec: r.Spec}
+}
+
+// validateClusterKronosApp applies the KronosApp validation, which requires
+// every included object to designate its namespaces.
+func (r *ClusterKronosApp) validateClusterKronosApp() error {
+	return r.asKronosApp().validateKronosApp()
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterKronosApp) ValidateCreate() (admission.Warnings, error) {
+	clusterkronosapplog.Info("validate create", "name", r.Name)
+	err := r.validateClusterKronosApp()
+	if err != nil {
+		return []string{err.Error()}, err
+	}
+	return warnOverlaps(fmt.Sprintf("ClusterKronosApp %s", r.Name), r.Spec), nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterKronosApp) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	clusterkronosapplog.Info("validate update", "name", r.Name)
+	err := r.validateClusterKronosApp()
+	if err != nil {
+		return []string{err.Error()}, err
+	}
+	return warnOverlaps(fmt.Sprintf("ClusterKronosApp %s", r.Name), r.Spec), nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterKronosApp) ValidateDelete() (admission.Warnings, error) {
+	clusterkronosapplog.Info("validate delete", "name", r.Name)
+	return nil, nil
+}
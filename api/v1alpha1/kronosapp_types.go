@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"time"
 
@@ -33,11 +34,81 @@ type Holiday struct {
 type IncludedObject struct {
 	ApiVersion string `json:"apiVersion"`
 	Kind       string `json:"kind"`
-	Namespace  string `json:"namespace"`
+	Namespace  string `json:"namespace,omitempty"`
 	IncludeRef string `json:"includeRef"`
 	ExcludeRef string `json:"excludeRef"`
+	// LabelSelector restricts the included objects to those matching the
+	// given labels, on top of IncludeRef and ExcludeRef.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// NamespaceSelector and NamespaceRef target every namespace matching
+	// the given labels and name pattern, including namespaces created later,
+	// in place of Namespace.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	NamespaceRef      string                `json:"namespaceRef,omitempty"`
+	// SleepReplicas is the number of replicas the included Deployments,
+	// StatefulSets and ReplicaSets keep while asleep, zero by default. The
+	// kronos.wecraft.tn/sleep-replicas annotation of a workload overrides it.
+	// +kubebuilder:validation:Minimum=0
+	SleepReplicas *int32 `json:"sleepReplicas,omitempty"`
 }
 
+// SleepingService is the Service the included Ingresses are routed to while
+// asleep, typically serving a page announcing when the application wakes up.
+// It must live in the namespace of each included Ingress.
+type SleepingService struct {
+	Name string `json:"name"`
+	Port int32  `json:"port"`
+}
+
+// ResourceConflict is an object included by several KronosApps or
+// ClusterKronosApps, only one of them handling it.
+type ResourceConflict struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Owner is the KronosApp handling the object.
+	Owner string `json:"owner"`
+	// Claimants lists every KronosApp including the object.
+	Claimants []string `json:"claimants"`
+}
+
+// ManagedResource reports what the KronosApp did to one of the objects it
+// handles.
+type ManagedResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// OriginalReplicas and OriginalSuspend are the replicas of a workload and
+	// the suspend field of a CronJob, Kustomization or HelmRelease restored
+	// on wake.
+	OriginalReplicas *int32 `json:"originalReplicas,omitempty"`
+	OriginalSuspend  *bool  `json:"originalSuspend,omitempty"`
+	// State is Asleep or Awake.
+	State string `json:"state"`
+	// LastAction is the last action taken on the object: Sleep, Resleep,
+	// Adopt, Report or Wake.
+	LastAction string `json:"lastAction,omitempty"`
+	// LastError is the error met by the last action, if any.
+	LastError string `json:"lastError,omitempty"`
+	// Failures counts the consecutive failures of the last action, which is
+	// retried with an exponential backoff from NextRetryTime on.
+	Failures      int32        `json:"failures,omitempty"`
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+const (
+	ResourceStateAsleep = "Asleep"
+	ResourceStateAwake  = "Awake"
+)
+
+const (
+	ResourceActionSleep   = "Sleep"
+	ResourceActionResleep = "Resleep"
+	ResourceActionAdopt   = "Adopt"
+	ResourceActionReport  = "Report"
+	ResourceActionWake    = "Wake"
+)
+
 // KronosAppSpec defines the desired state of KronosApp
 type KronosAppSpec struct {
 	StartSleep      string           `json:"startSleep"`
@@ -48,23 +119,158 @@ type KronosAppSpec struct {
 	IncludedObjects []IncludedObject `json:"includedObjects"`
 	ForceWake       bool             `json:"forceWake,omitempty"`
 	ForceSleep      bool             `json:"forceSleep,omitempty"`
+	SleepingService *SleepingService `json:"sleepingService,omitempty"`
+	// WakeUntil keeps the included objects awake until the given time,
+	// regardless of the schedule. It is set by the activator when a request
+	// reaches a sleeping application.
+	WakeUntil *metav1.Time `json:"wakeUntil,omitempty"`
+	// DeletionPolicy tells what becomes of the objects asleep when the
+	// KronosApp is deleted: restore wakes them up, leave-asleep leaves them
+	// as they are.
+	// +kubebuilder:validation:Enum=restore;leave-asleep
+	// +kubebuilder:default=restore
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+	// DriftPolicy tells what is done with the objects changed by anything but
+	// the controller while asleep: re-sleep puts them back to sleep, adopt
+	// records their new state as the one restored on wake before putting them
+	// back to sleep, report leaves them as they are.
+	// +kubebuilder:validation:Enum=re-sleep;adopt;report
+	// +kubebuilder:default=re-sleep
+	DriftPolicy string `json:"driftPolicy,omitempty"`
+	// DryRun makes the controller compute the schedule and the included
+	// objects and report what it would do in the status, Events and metrics,
+	// without changing the objects or writing their state.
+	DryRun bool `json:"dryRun,omitempty"`
+	// Suspend stops applying the schedule until it is unset. SuspendPolicy
+	// tells what becomes of the objects asleep meanwhile: restore wakes them
+	// up, leave-as-is leaves them as they are.
+	Suspend bool `json:"suspend,omitempty"`
+	// +kubebuilder:validation:Enum=restore;leave-as-is
+	// +kubebuilder:default=leave-as-is
+	SuspendPolicy string `json:"suspendPolicy,omitempty"`
+}
+
+const (
+	DeletionPolicyRestore     = "restore"
+	DeletionPolicyLeaveAsleep = "leave-asleep"
+)
+
+const (
+	SuspendPolicyRestore   = "restore"
+	SuspendPolicyLeaveAsIs = "leave-as-is"
+)
+
+const (
+	DriftPolicyResleep = "re-sleep"
+	DriftPolicyAdopt   = "adopt"
+	DriftPolicyReport  = "report"
+)
+
+// SkippedResource is a resource matching the included objects that is left
+// untouched by the KronosApp, along with the reason why.
+type SkippedResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
 }
 
 // KronosAppStatus defines the observed state of KronosApp
 type KronosAppStatus struct {
-	Status           string   `json:"status"`
-	Reason           string   `json:"reason"`
-	HandledResources string   `json:"handledResources"`
-	NextOperation    string   `json:"nextOperation"`
-	CreatedSecrets   []string `json:"secretCreated,omitempty"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+	// HandledResources is the number of objects handled by the KronosApp.
+	HandledResources int32 `json:"handledResources"`
+	// NextOperation and NextTransitionTime tell when the KronosApp next goes
+	// to sleep or wakes up.
+	NextOperation      string       `json:"nextOperation"`
+	NextTransitionTime *metav1.Time `json:"nextTransitionTime,omitempty"`
+	// ObservedGeneration is the generation of the spec the status reflects.
+	ObservedGeneration int64             `json:"observedGeneration,omitempty"`
+	CreatedSecrets     []string          `json:"secretCreated,omitempty"`
+	SkippedResources   []SkippedResource `json:"skippedResources,omitempty"`
+	// Conflicts lists the objects included by several KronosApps, along with
+	// the one handling them.
+	Conflicts []ResourceConflict `json:"conflicts,omitempty"`
+	// Resources lists the objects handled by the KronosApp, those met with
+	// an error first. Every object met with an error is listed, as its
+	// backoff is kept there, the others up to a hundred objects in all.
+	// ResourcesOmitted counts the objects left out.
+	// +listType=atomic
+	Resources        []ManagedResource `json:"resources,omitempty"`
+	ResourcesOmitted int32             `json:"resourcesOmitted,omitempty"`
+	// DryRun is true when the status reports what the controller would do,
+	// the included objects being left untouched.
+	DryRun bool `json:"dryRun,omitempty"`
+	// Conditions holds the Ready, Asleep, Degraded, ScheduleValid,
+	// StateStoreHealthy and Suspended conditions of the KronosApp.
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// UnmarshalJSON decodes a status, including those written by earlier
+// releases in which handledResources was a string.
+func (s *KronosAppStatus) UnmarshalJSON(data []byte) error {
+	type status KronosAppStatus
+	var decoded struct {
+		status
+		HandledResources json.RawMessage `json:"handledResources,omitempty"`
+	}
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+	*s = KronosAppStatus(decoded.status)
+	if len(decoded.HandledResources) == 0 || string(decoded.HandledResources) == "null" {
+		return nil
+	}
+	var handledResources string
+	if json.Unmarshal(decoded.HandledResources, &handledResources) == nil {
+		count, err := strconv.ParseInt(handledResources, 10, 32)
+		if err != nil {
+			return err
+		}
+		s.HandledResources = int32(count)
+		return nil
+	}
+	return json.Unmarshal(decoded.HandledResources, &s.HandledResources)
 }
 
+// StatusSuspended is the status of a KronosApp whose schedule is suspended.
+const StatusSuspended = "Suspended"
+
+// The condition types of a KronosApp.
+const (
+	// ConditionReady is true when the last reconciliation applied the
+	// schedule to every included object.
+	ConditionReady = "Ready"
+	// ConditionAsleep is true while the included objects are asleep.
+	ConditionAsleep = "Asleep"
+	// ConditionDegraded is true when some included objects could not be put
+	// to sleep or woken up.
+	ConditionDegraded = "Degraded"
+	// ConditionScheduleValid is false when the schedule or the included
+	// objects cannot be understood.
+	ConditionScheduleValid = "ScheduleValid"
+	// ConditionStateStoreHealthy is false when the state store cannot be
+	// read or was changed by anything but the controller.
+	ConditionStateStoreHealthy = "StateStoreHealthy"
+	// ConditionSuspended is true while the schedule is not applied because
+	// spec.suspend is set.
+	ConditionSuspended = "Suspended"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status"
 // +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.reason"
-// +kubebuilder:printcolumn:name="Handled Resources",type="string",JSONPath=".status.handledResources"
+// +kubebuilder:printcolumn:name="Handled Resources",type="integer",JSONPath=".status.handledResources"
 // +kubebuilder:printcolumn:name="Next Operation",type="string",JSONPath=".status.nextOperation"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Dry Run",type="boolean",JSONPath=".status.dryRun",priority=1
 
 // KronosApp is the Schema for the kronosapps API
 type KronosApp struct {
@@ -84,11 +290,42 @@ type KronosAppList struct {
 	Items           []KronosApp `json:"items"`
 }
 
-func (k KronosApp) GetNewKronosAppStatus(status, reason bool, nextOperation time.Time, handledResources int) KronosAppStatus {
+// KronosAppObject is implemented by KronosApp and ClusterKronosApp, which
+// share the same schedule and resource selection.
+// +kubebuilder:object:generate=false
+type KronosAppObject interface {
+	client.Object
+	GetSpec() *KronosAppSpec
+	GetStatus() *KronosAppStatus
+}
+
+var _ KronosAppObject = &KronosApp{}
+
+func (k *KronosApp) GetSpec() *KronosAppSpec {
+	return &k.Spec
+}
+
+func (k *KronosApp) GetStatus() *KronosAppStatus {
+	return &k.Status
+}
+
+// IsWakeOverrideActive reports whether the included objects are kept awake by
+// WakeUntil at the given time.
+func (spec KronosAppSpec) IsWakeOverrideActive(now time.Time) bool {
+	return spec.WakeUntil != nil && now.Before(spec.WakeUntil.Time)
+}
+
+func (k KronosApp) IsWakeOverrideActive(now time.Time) bool {
+	return k.Spec.IsWakeOverrideActive(now)
+}
+
+// GetNewKronosAppStatus returns the status of a KronosApp evaluated at the
+// given time, the one its schedule was evaluated at.
+func (spec KronosAppSpec) GetNewKronosAppStatus(status, reason bool, now, nextOperation time.Time, handledResources int) KronosAppStatus {
 	newStatus := KronosAppStatus{}
 	if status {
 		newStatus.Status = "Asleep"
-		if k.Spec.ForceSleep {
+		if spec.ForceSleep {
 			newStatus.Reason = "ForceSleep"
 		} else if reason {
 			newStatus.Reason = "Holiday"
@@ -97,20 +334,33 @@ func (k KronosApp) GetNewKronosAppStatus(status, reason bool, nextOperation time
 		}
 	} else {
 		newStatus.Status = "Awake"
-		if k.Spec.ForceWake {
+		if spec.ForceWake {
 			newStatus.Reason = "ForceWake"
+		} else if spec.IsWakeOverrideActive(now) {
+			newStatus.Reason = "WakeOverride"
 		} else {
 			newStatus.Reason = "Scheduled"
 		}
 	}
-	newStatus.HandledResources = strconv.Itoa(handledResources)
+	newStatus.HandledResources = int32(handledResources)
 	newStatus.NextOperation = nextOperation.String()
+	newStatus.NextTransitionTime = &metav1.Time{Time: nextOperation}
 	return newStatus
 }
 
+func (k KronosApp) GetNewKronosAppStatus(status, reason bool, now, nextOperation time.Time, handledResources int) KronosAppStatus {
+	return k.Spec.GetNewKronosAppStatus(status, reason, now, nextOperation, handledResources)
+}
+
 func (k KronosApp) SetNewKronosAppStatus(ctx context.Context, Client client.Client, newStatus KronosAppStatus) error {
-	kdc := k.DeepCopy()
-	kdc.Status = newStatus
+	return SetNewKronosAppStatus(ctx, Client, &k, newStatus)
+}
+
+// SetNewKronosAppStatus updates the status subresource of a KronosApp or a
+// ClusterKronosApp.
+func SetNewKronosAppStatus(ctx context.Context, Client client.Client, kronosApp KronosAppObject, newStatus KronosAppStatus) error {
+	kdc := kronosApp.DeepCopyObject().(KronosAppObject)
+	*kdc.GetStatus() = newStatus
 	err := Client.Status().Update(ctx, kdc)
 	if err != nil {
 		return err
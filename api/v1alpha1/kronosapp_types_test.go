@@ -0,0 +1,42 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestKronosAppStatusUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expected  int32
+		expectErr bool
+	}{
+		{name: "string count", data: `{"status":"Asleep","handledResources":"3"}`, expected: 3},
+		{name: "numeric count", data: `{"status":"Asleep","handledResources":3}`, expected: 3},
+		{name: "null count", data: `{"status":"Asleep","handledResources":null}`},
+		{name: "absent count", data: `{"status":"Asleep"}`},
+		{name: "non-numeric string", data: `{"status":"Asleep","handledResources":"three"}`, expectErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var status KronosAppStatus
+			err := json.Unmarshal([]byte(test.data), &status)
+			if test.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.HandledResources != test.expected {
+				t.Errorf("expected %d handled resources, got %d", test.expected, status.HandledResources)
+			}
+			if status.Status != "Asleep" {
+				t.Errorf("expected the other fields to be decoded, got %q", status.Status)
+			}
+		})
+	}
+}
@@ -17,13 +17,16 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -32,8 +35,13 @@ import (
 // log is for logging in this package.
 var kronosapplog = logf.Log.WithName("kronosapp-resource")
 
+// webhookClient is used to look for KronosApps and ClusterKronosApps
+// overlapping the validated one.
+var webhookClient client.Reader
+
 // SetupWebhookWithManager will setup the manager to manage the webhooks
 func (r *KronosApp) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetAPIReader()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -49,14 +57,15 @@ func (r *KronosApp) Default() {
 	if r.Spec.WeekDays == "" {
 		r.Spec.WeekDays = "*"
 	}
-	for _, includedObject := range r.Spec.IncludedObjects {
+	for index := range r.Spec.IncludedObjects {
+		includedObject := &r.Spec.IncludedObjects[index]
 		if includedObject.ApiVersion == "" {
 			includedObject.ApiVersion = "*"
 		}
 		if includedObject.Kind == "" {
 			includedObject.Kind = "*"
 		}
-		if includedObject.Namespace == "" {
+		if includedObject.Namespace == "" && includedObject.NamespaceSelector == nil && includedObject.NamespaceRef == "" {
 			includedObject.Namespace = "default"
 		}
 		if includedObject.IncludeRef == "" {
@@ -80,7 +89,7 @@ func (r *KronosApp) ValidateCreate() (admission.Warnings, error) {
 	if err != nil {
 		return []string{err.Error()}, err
 	}
-	return nil, nil
+	return warnOverlaps(fmt.Sprintf("KronosApp %s/%s", r.Namespace, r.Name), r.Spec), nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
@@ -90,7 +99,7 @@ func (r *KronosApp) ValidateUpdate(old runtime.Object) (admission.Warnings, erro
 	if err != nil {
 		return []string{err.Error()}, err
 	}
-	return nil, nil
+	return warnOverlaps(fmt.Sprintf("KronosApp %s/%s", r.Namespace, r.Name), r.Spec), nil
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -101,6 +110,66 @@ func (r *KronosApp) ValidateDelete() (admission.Warnings, error) {
 	return nil, nil
 }
 
+// includedObjectsMayOverlap reports whether two included objects may select
+// the same objects. Name patterns are not compared, so that the answer errs
+// on the side of caution.
+func includedObjectsMayOverlap(a, b IncludedObject) bool {
+	if a.ApiVersion != b.ApiVersion && a.ApiVersion != "*" && b.ApiVersion != "*" {
+		return false
+	}
+	if a.Kind != b.Kind && a.Kind != "*" && b.Kind != "*" {
+		return false
+	}
+	if a.NamespaceSelector != nil || a.NamespaceRef != "" || b.NamespaceSelector != nil || b.NamespaceRef != "" {
+		return true
+	}
+	return a.Namespace == b.Namespace || a.Namespace == "" || b.Namespace == ""
+}
+
+// MayOverlap reports whether two KronosApps may include the same objects.
+func (spec KronosAppSpec) MayOverlap(other KronosAppSpec) bool {
+	for _, includedObject := range spec.IncludedObjects {
+		for _, otherIncludedObject := range other.IncludedObjects {
+			if includedObjectsMayOverlap(includedObject, otherIncludedObject) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// warnOverlaps warns about the other KronosApps and ClusterKronosApps that may
+// include the same objects as the validated one, named self. Each object is
+// only handled by one of them, the conflicts being reported in their status.
+func warnOverlaps(self string, spec KronosAppSpec) admission.Warnings {
+	if webhookClient == nil {
+		return nil
+	}
+	var warnings admission.Warnings
+	warn := func(other string, otherSpec KronosAppSpec) {
+		if other != self && spec.MayOverlap(otherSpec) {
+			warnings = append(warnings, fmt.Sprintf("Included objects may overlap with %s. Objects included by both are only handled by one of them, as reported in their status.", other))
+		}
+	}
+	kronosApps := &KronosAppList{}
+	err := webhookClient.List(context.TODO(), kronosApps)
+	if err != nil {
+		kronosapplog.Error(err, "listing KronosApps", "kronosapp", self)
+	}
+	for _, kronosApp := range kronosApps.Items {
+		warn(fmt.Sprintf("KronosApp %s/%s", kronosApp.Namespace, kronosApp.Name), kronosApp.Spec)
+	}
+	clusterKronosApps := &ClusterKronosAppList{}
+	err = webhookClient.List(context.TODO(), clusterKronosApps)
+	if err != nil {
+		kronosapplog.Error(err, "listing ClusterKronosApps", "kronosapp", self)
+	}
+	for _, clusterKronosApp := range clusterKronosApps.Items {
+		warn(fmt.Sprintf("ClusterKronosApp %s", clusterKronosApp.Name), clusterKronosApp.Spec)
+	}
+	return warnings
+}
+
 func (r *KronosApp) validateScheduleStartTime() error {
 	_, err := time.Parse("15:04", r.Spec.StartSleep)
 	if err != nil {
@@ -148,6 +217,64 @@ func (r *KronosApp) validateScheduleHolidays() error {
 	return nil
 }
 
+func (r *KronosApp) validateLabelSelectors() error {
+	for _, includedObject := range r.Spec.IncludedObjects {
+		_, err := metav1.LabelSelectorAsSelector(includedObject.LabelSelector)
+		if err != nil {
+			return fmt.Errorf("Label selector of included object in namespace %s is invalid: %s", includedObject.Namespace, err.Error())
+		}
+		_, err = metav1.LabelSelectorAsSelector(includedObject.NamespaceSelector)
+		if err != nil {
+			return fmt.Errorf("Namespace selector of included object is invalid: %s", err.Error())
+		}
+		_, err = regexp.Compile(includedObject.NamespaceRef)
+		if err != nil {
+			return fmt.Errorf("Namespace pattern %s of included object is invalid.", includedObject.NamespaceRef)
+		}
+	}
+	return nil
+}
+
+// validateNamespaces requires every included object to designate its
+// namespaces, so that it never targets the whole cluster, system namespaces
+// included, by mistake.
+func (r *KronosApp) validateNamespaces() error {
+	for _, includedObject := range r.Spec.IncludedObjects {
+		if includedObject.Namespace == "" && includedObject.NamespaceSelector == nil && includedObject.NamespaceRef == "" {
+			return errors.New("Included objects must set a namespace, a namespace selector or a namespace pattern.")
+		}
+	}
+	return nil
+}
+
+// includesIngresses reports whether Ingresses are among the kinds fetched for
+// an included object: the networking.k8s.io/v1 apiVersion is fetched as
+// Ingresses whatever the kind, and the Ingress kind of any apiVersion too.
+func (includedObject IncludedObject) includesIngresses() bool {
+	switch includedObject.ApiVersion {
+	case "networking.k8s.io/v1":
+		return true
+	case "*":
+		return includedObject.Kind == "Ingress"
+	}
+	return false
+}
+
+func (r *KronosApp) validateSleepingService() error {
+	for _, includedObject := range r.Spec.IncludedObjects {
+		if !includedObject.includesIngresses() {
+			continue
+		}
+		if r.Spec.SleepingService == nil || r.Spec.SleepingService.Name == "" {
+			return errors.New("A sleeping service is required to include Ingresses.")
+		}
+		if r.Spec.SleepingService.Port <= 0 {
+			return errors.New("Sleeping service port is invalid.")
+		}
+	}
+	return nil
+}
+
 func (r *KronosApp) validateKronosApp() error {
 	err := r.validateScheduleStartTime()
 	if err != nil {
@@ -169,5 +296,17 @@ func (r *KronosApp) validateKronosApp() error {
 	if err != nil {
 		return err
 	}
+	err = r.validateNamespaces()
+	if err != nil {
+		return err
+	}
+	err = r.validateLabelSelectors()
+	if err != nil {
+		return err
+	}
+	err = r.validateSleepingService()
+	if err != nil {
+		return err
+	}
 	return nil
 }
@@ -21,9 +21,69 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKronosApp) DeepCopyInto(out *ClusterKronosApp) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKronosApp.
+func (in *ClusterKronosApp) DeepCopy() *ClusterKronosApp {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKronosApp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKronosApp) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKronosAppList) DeepCopyInto(out *ClusterKronosAppList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterKronosApp, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKronosAppList.
+func (in *ClusterKronosAppList) DeepCopy() *ClusterKronosAppList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKronosAppList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKronosAppList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Holiday) DeepCopyInto(out *Holiday) {
 	*out = *in
@@ -42,6 +102,21 @@ func (in *Holiday) DeepCopy() *Holiday {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IncludedObject) DeepCopyInto(out *IncludedObject) {
 	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SleepReplicas != nil {
+		in, out := &in.SleepReplicas, &out.SleepReplicas
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IncludedObject.
@@ -124,7 +199,18 @@ func (in *KronosAppSpec) DeepCopyInto(out *KronosAppSpec) {
 	if in.IncludedObjects != nil {
 		in, out := &in.IncludedObjects, &out.IncludedObjects
 		*out = make([]IncludedObject, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SleepingService != nil {
+		in, out := &in.SleepingService, &out.SleepingService
+		*out = new(SleepingService)
+		**out = **in
+	}
+	if in.WakeUntil != nil {
+		in, out := &in.WakeUntil, &out.WakeUntil
+		*out = (*in).DeepCopy()
 	}
 }
 
@@ -141,11 +227,41 @@ func (in *KronosAppSpec) DeepCopy() *KronosAppSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KronosAppStatus) DeepCopyInto(out *KronosAppStatus) {
 	*out = *in
+	if in.NextTransitionTime != nil {
+		in, out := &in.NextTransitionTime, &out.NextTransitionTime
+		*out = (*in).DeepCopy()
+	}
 	if in.CreatedSecrets != nil {
 		in, out := &in.CreatedSecrets, &out.CreatedSecrets
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.SkippedResources != nil {
+		in, out := &in.SkippedResources, &out.SkippedResources
+		*out = make([]SkippedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]ResourceConflict, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ManagedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KronosAppStatus.
@@ -157,3 +273,82 @@ func (in *KronosAppStatus) DeepCopy() *KronosAppStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedResource) DeepCopyInto(out *ManagedResource) {
+	*out = *in
+	if in.OriginalReplicas != nil {
+		in, out := &in.OriginalReplicas, &out.OriginalReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OriginalSuspend != nil {
+		in, out := &in.OriginalSuspend, &out.OriginalSuspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedResource.
+func (in *ManagedResource) DeepCopy() *ManagedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceConflict) DeepCopyInto(out *ResourceConflict) {
+	*out = *in
+	if in.Claimants != nil {
+		in, out := &in.Claimants, &out.Claimants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceConflict.
+func (in *ResourceConflict) DeepCopy() *ResourceConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedResource) DeepCopyInto(out *SkippedResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedResource.
+func (in *SkippedResource) DeepCopy() *SkippedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SleepingService) DeepCopyInto(out *SleepingService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SleepingService.
+func (in *SleepingService) DeepCopy() *SleepingService {
+	if in == nil {
+		return nil
+	}
+	out := new(SleepingService)
+	in.DeepCopyInto(out)
+	return out
+}
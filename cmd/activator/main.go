@@ -0,0 +1,163 @@
+/*
+Copyright 2024 IsmailAbdelkefi.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	// to ensure that exec-entrypoint and run can make use of them.
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	v1alpha1 "github.com/KronosOrg/kronos-core/api/v1alpha1"
+	"github.com/KronosOrg/kronos-core/internal/activator"
+)
+
+var (
+	scheme   = runtime.NewScheme()
+	setupLog = ctrl.Log.WithName("setup")
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+}
+
+func main() {
+	var listenAddr string
+	var probeAddr string
+	var kronosAppName string
+	var kronosAppNamespace string
+	var serviceName string
+	var serviceNamespace string
+	var servicePort int
+	var idleTimeout time.Duration
+	var wakeTimeout time.Duration
+	flag.StringVar(&listenAddr, "listen-address", ":8080", "The address the activator proxy binds to.")
+	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&kronosAppName, "kronosapp", "", "Name of the KronosApp putting the application to sleep.")
+	flag.StringVar(&kronosAppNamespace, "kronosapp-namespace", os.Getenv("POD_NAMESPACE"), "Namespace of the KronosApp.")
+	flag.StringVar(&serviceName, "service", "", "Name of the Service requests are forwarded to once the application is awake.")
+	flag.StringVar(&serviceNamespace, "service-namespace", "", "Namespace of the Service, defaults to the namespace of the KronosApp.")
+	flag.IntVar(&servicePort, "service-port", 80, "Port of the Service requests are forwarded to.")
+	flag.DurationVar(&idleTimeout, "idle-timeout", 30*time.Minute,
+		"Duration without requests after which the application is put back to sleep.")
+	flag.DurationVar(&wakeTimeout, "wake-timeout", 2*time.Minute,
+		"Maximum duration a request is held while the application wakes up.")
+	opts := zap.Options{
+		Development: true,
+	}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	if kronosAppName == "" || kronosAppNamespace == "" || serviceName == "" {
+		setupLog.Error(errors.New("missing required flags"), "--kronosapp, --kronosapp-namespace and --service are required")
+		os.Exit(1)
+	}
+	if serviceNamespace == "" {
+		serviceNamespace = kronosAppNamespace
+	}
+	target, err := url.Parse(fmt.Sprintf("http://%s.%s.svc:%d", serviceName, serviceNamespace, servicePort))
+	if err != nil {
+		setupLog.Error(err, "unable to build the target URL")
+		os.Exit(1)
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme: scheme,
+		Metrics: metricsserver.Options{
+			BindAddress: "0",
+		},
+		HealthProbeBindAddress: probeAddr,
+		// Only the Endpoints of the target Service are read, so the cache is
+		// restricted to its namespace.
+		Cache: cache.Options{
+			DefaultNamespaces: map[string]cache.Config{
+				serviceNamespace: {},
+			},
+		},
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+
+	proxy := activator.NewActivator(
+		mgr.GetClient(),
+		types.NamespacedName{Name: kronosAppName, Namespace: kronosAppNamespace},
+		types.NamespacedName{Name: serviceName, Namespace: serviceNamespace},
+		target,
+		idleTimeout,
+		wakeTimeout,
+	)
+	server := &http.Server{
+		Addr:              listenAddr,
+		Handler:           proxy,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		go func() {
+			<-ctx.Done()
+			_ = server.Shutdown(context.Background())
+		}()
+		setupLog.Info("starting activator proxy", "address", listenAddr, "target", target.String())
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}))
+	if err != nil {
+		setupLog.Error(err, "unable to set up activator proxy")
+		os.Exit(1)
+	}
+
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
+	setupLog.Info("starting manager")
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		setupLog.Error(err, "problem running manager")
+		os.Exit(1)
+	}
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -58,6 +59,9 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var operatorNamespace string
+	var stateStore string
+	var dryRun bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -67,6 +71,12 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&operatorNamespace, "operator-namespace", getEnvOrDefault("POD_NAMESPACE", "kronos-system"),
+		"The namespace holding the state of the objects put to sleep by ClusterKronosApps.")
+	flag.StringVar(&stateStore, "state-store", kronosappController.StateStoreSecret,
+		"Where the original state of the objects put to sleep is kept: secret, configmap or annotations.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"If set, every KronosApp reports what it would do without changing the objects or writing their state.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -75,6 +85,13 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	switch stateStore {
+	case kronosappController.StateStoreSecret, kronosappController.StateStoreConfigMap, kronosappController.StateStoreAnnotations:
+	default:
+		setupLog.Error(fmt.Errorf("unknown state store %q", stateStore), "invalid flag", "flag", "state-store")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancelation and
@@ -126,18 +143,41 @@ func main() {
 	additionalMetrics := kronosappController.RegisterMetrics().MustRegister(ctrlMetrics.Registry)
 
 	if err = (&kronosappController.KronosAppReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Metrics: additionalMetrics,
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Metrics:    additionalMetrics,
+		StateStore: stateStore,
+		Recorder:   mgr.GetEventRecorderFor("kronosapp-controller"),
+		DryRun:     dryRun,
+		APIReader:  mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KronosApp")
 		os.Exit(1)
 	}
+	if err = (&kronosappController.ClusterKronosAppReconciler{
+		KronosAppReconciler: kronosappController.KronosAppReconciler{
+			Client:     mgr.GetClient(),
+			Scheme:     mgr.GetScheme(),
+			Metrics:    additionalMetrics,
+			StateStore: stateStore,
+			Recorder:   mgr.GetEventRecorderFor("clusterkronosapp-controller"),
+			DryRun:     dryRun,
+			APIReader:  mgr.GetAPIReader(),
+		},
+		StateNamespace: operatorNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterKronosApp")
+		os.Exit(1)
+	}
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		if err = (&v1alpha1.KronosApp{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "KronosApp")
 			os.Exit(1)
 		}
+		if err = (&v1alpha1.ClusterKronosApp{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterKronosApp")
+			os.Exit(1)
+		}
 	}
 	//+kubebuilder:scaffold:builder
 
@@ -156,3 +196,10 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,119 @@
+package activator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Activator sits in front of the Service of a sleeping application. Incoming
+// requests keep the owning KronosApp awake through its WakeUntil override and
+// are held until the Service has ready endpoints, then forwarded to it.
+type Activator struct {
+	Client       client.Client
+	KronosApp    types.NamespacedName
+	Service      types.NamespacedName
+	IdleTimeout  time.Duration
+	WakeTimeout  time.Duration
+	PollInterval time.Duration
+
+	proxy     *httputil.ReverseProxy
+	mu        sync.Mutex
+	wakeUntil time.Time
+}
+
+func NewActivator(Client client.Client, kronosApp, service types.NamespacedName, target *url.URL, idleTimeout, wakeTimeout time.Duration) *Activator {
+	return &Activator{
+		Client:       Client,
+		KronosApp:    kronosApp,
+		Service:      service,
+		IdleTimeout:  idleTimeout,
+		WakeTimeout:  wakeTimeout,
+		PollInterval: time.Second,
+		proxy:        httputil.NewSingleHostReverseProxy(target),
+	}
+}
+
+func (a *Activator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l := log.FromContext(r.Context())
+	err := a.extendWakeOverride(r.Context())
+	if err != nil {
+		l.Error(err, "Extending Wake Override", "kronosapp", a.KronosApp)
+		http.Error(w, "unable to wake the application up", http.StatusServiceUnavailable)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), a.WakeTimeout)
+	defer cancel()
+	err = a.waitForReadiness(ctx)
+	if err != nil {
+		l.Error(err, "Waiting For Readiness", "service", a.Service)
+		w.Header().Set("Retry-After", "10")
+		http.Error(w, "the application is waking up, please retry in a moment", http.StatusServiceUnavailable)
+		return
+	}
+	a.proxy.ServeHTTP(w, r)
+}
+
+// extendWakeOverride pushes the WakeUntil override of the KronosApp back to
+// one idle timeout from now. To spare the API server, the KronosApp is only
+// patched once half of the idle timeout has elapsed since the last extension.
+func (a *Activator) extendWakeOverride(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	if a.wakeUntil.Sub(now) > a.IdleTimeout/2 {
+		return nil
+	}
+	wakeUntil := metav1.NewTime(now.Add(a.IdleTimeout))
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"wakeUntil": wakeUntil,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	kronosApp := &v1alpha1.KronosApp{}
+	kronosApp.SetName(a.KronosApp.Name)
+	kronosApp.SetNamespace(a.KronosApp.Namespace)
+	err = a.Client.Patch(ctx, kronosApp, client.RawPatch(types.MergePatchType, patch))
+	if err != nil {
+		return err
+	}
+	a.wakeUntil = wakeUntil.Time
+	return nil
+}
+
+func (a *Activator) isReady(ctx context.Context) (bool, error) {
+	endpoints := &corev1.Endpoints{}
+	err := a.Client.Get(ctx, a.Service, endpoints)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (a *Activator) waitForReadiness(ctx context.Context) error {
+	return wait.PollUntilContextCancel(ctx, a.PollInterval, true, a.isReady)
+}
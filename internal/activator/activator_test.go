@@ -0,0 +1,160 @@
+package activator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var (
+	testKronosApp = types.NamespacedName{Name: "app", Namespace: "default"}
+	testService   = types.NamespacedName{Name: "web", Namespace: "default"}
+)
+
+// newTestActivator returns an Activator backed by a fake client holding the
+// given objects besides the KronosApp, and the count of patches it sent.
+func newTestActivator(t *testing.T, target *url.URL, objects ...client.Object) (*Activator, *int) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	kronosApp := &v1alpha1.KronosApp{ObjectMeta: metav1.ObjectMeta{Name: testKronosApp.Name, Namespace: testKronosApp.Namespace}}
+	patches := 0
+	Client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append(objects, kronosApp)...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				patches++
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).
+		Build()
+	if target == nil {
+		target = &url.URL{Scheme: "http", Host: "localhost"}
+	}
+	a := NewActivator(Client, testKronosApp, testService, target, time.Hour, 50*time.Millisecond)
+	a.PollInterval = 10 * time.Millisecond
+	return a, &patches
+}
+
+func newTestEndpoints(ready bool) *corev1.Endpoints {
+	endpoints := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: testService.Name, Namespace: testService.Namespace}}
+	if ready {
+		endpoints.Subsets = []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}}
+	} else {
+		endpoints.Subsets = []corev1.EndpointSubset{{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}}
+	}
+	return endpoints
+}
+
+func TestExtendWakeOverride(t *testing.T) {
+	ctx := context.Background()
+	a, patches := newTestActivator(t, nil)
+
+	before := time.Now()
+	if err := a.extendWakeOverride(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if *patches != 1 {
+		t.Fatalf("expected the KronosApp to be patched once, got %d", *patches)
+	}
+	kronosApp := &v1alpha1.KronosApp{}
+	if err := a.Client.Get(ctx, testKronosApp, kronosApp); err != nil {
+		t.Fatal(err)
+	}
+	if kronosApp.Spec.WakeUntil == nil || kronosApp.Spec.WakeUntil.Time.Before(before.Add(a.IdleTimeout).Truncate(time.Second)) {
+		t.Errorf("expected wakeUntil to be one idle timeout from now, got %v", kronosApp.Spec.WakeUntil)
+	}
+
+	if err := a.extendWakeOverride(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if *patches != 1 {
+		t.Errorf("expected no patch within half of the idle timeout, got %d", *patches)
+	}
+
+	a.wakeUntil = time.Now().Add(a.IdleTimeout/2 - time.Minute)
+	if err := a.extendWakeOverride(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if *patches != 2 {
+		t.Errorf("expected a patch once half of the idle timeout elapsed, got %d", *patches)
+	}
+}
+
+func TestWaitForReadiness(t *testing.T) {
+	tests := []struct {
+		name      string
+		objects   []client.Object
+		expectErr bool
+	}{
+		{name: "ready endpoints", objects: []client.Object{newTestEndpoints(true)}},
+		{name: "not ready endpoints", objects: []client.Object{newTestEndpoints(false)}, expectErr: true},
+		{name: "missing endpoints", expectErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, _ := newTestActivator(t, nil, test.objects...)
+			ctx, cancel := context.WithTimeout(context.Background(), a.WakeTimeout)
+			defer cancel()
+			err := a.waitForReadiness(ctx)
+			if test.expectErr != (err != nil) {
+				t.Errorf("expected error %v, got %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer backend.Close()
+	target, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		ready      bool
+		statusCode int
+		retryAfter string
+	}{
+		{name: "forwarded when ready", ready: true, statusCode: http.StatusTeapot},
+		{name: "held then rejected when not ready", statusCode: http.StatusServiceUnavailable, retryAfter: "10"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, patches := newTestActivator(t, target, newTestEndpoints(test.ready))
+			recorder := httptest.NewRecorder()
+			a.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != test.statusCode {
+				t.Errorf("expected status %d, got %d", test.statusCode, recorder.Code)
+			}
+			if recorder.Header().Get("Retry-After") != test.retryAfter {
+				t.Errorf("expected Retry-After %q, got %q", test.retryAfter, recorder.Header().Get("Retry-After"))
+			}
+			if *patches != 1 {
+				t.Errorf("expected the wake override to be extended, got %d patches", *patches)
+			}
+		})
+	}
+}
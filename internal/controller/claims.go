@@ -0,0 +1,195 @@
+package kronosapp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// claimant is a KronosApp or a ClusterKronosApp including an object.
+type claimant struct {
+	name              string
+	clusterScoped     bool
+	creationTimestamp metav1.Time
+}
+
+func getKronosAppKind(kronosApp v1alpha1.KronosAppObject) string {
+	if _, ok := kronosApp.(*v1alpha1.ClusterKronosApp); ok {
+		return "ClusterKronosApp"
+	}
+	return "KronosApp"
+}
+
+func getClaimantName(kronosApp v1alpha1.KronosAppObject) string {
+	if kronosApp.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", getKronosAppKind(kronosApp), kronosApp.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", getKronosAppKind(kronosApp), kronosApp.GetNamespace(), kronosApp.GetName())
+}
+
+func getObjectKey(kind string, item metav1.Object) string {
+	return fmt.Sprintf("%s/%s/%s", kind, item.GetNamespace(), item.GetName())
+}
+
+func newClaimant(kronosApp v1alpha1.KronosAppObject) claimant {
+	_, clusterScoped := kronosApp.(*v1alpha1.ClusterKronosApp)
+	return claimant{
+		name:              getClaimantName(kronosApp),
+		clusterScoped:     clusterScoped,
+		creationTimestamp: kronosApp.GetCreationTimestamp(),
+	}
+}
+
+// isClaiming reports whether a KronosApp takes part in the election of the
+// owners of the objects it includes, which suspended KronosApps and those
+// being deleted do not. They still hold the objects they hold asleep.
+func isClaiming(kronosApp v1alpha1.KronosAppObject) bool {
+	return !kronosApp.GetSpec().Suspend && kronosApp.GetDeletionTimestamp().IsZero()
+}
+
+// listKronosApps returns every KronosApp and ClusterKronosApp.
+func listKronosApps(ctx context.Context, Client client.Client) ([]v1alpha1.KronosAppObject, error) {
+	var kronosApps []v1alpha1.KronosAppObject
+	kronosAppList := &v1alpha1.KronosAppList{}
+	err := Client.List(ctx, kronosAppList)
+	if err != nil {
+		return nil, err
+	}
+	for index := range kronosAppList.Items {
+		kronosApps = append(kronosApps, &kronosAppList.Items[index])
+	}
+	clusterKronosAppList := &v1alpha1.ClusterKronosAppList{}
+	err = Client.List(ctx, clusterKronosAppList)
+	if err != nil {
+		return nil, err
+	}
+	for index := range clusterKronosAppList.Items {
+		kronosApps = append(kronosApps, &clusterKronosAppList.Items[index])
+	}
+	return kronosApps, nil
+}
+
+// getClaims returns the KronosApps and ClusterKronosApps including each of
+// the objects included by a KronosApp, keyed by kind, namespace and name. The
+// objects are matched against the included objects of the other KronosApps
+// rather than fetched again for each of them, those whose included objects
+// are invalid being left out. The KronosApps no longer claiming objects are
+// only listed for those they hold asleep.
+func getClaims(ctx context.Context, Client client.Client, kronosApp v1alpha1.KronosAppObject, includedObjects *ObjectList) (map[string][]claimant, error) {
+	l := log.Log
+	kronosApps, err := listKronosApps(ctx, Client)
+	if err != nil {
+		return nil, err
+	}
+	self := getClaimantName(kronosApp)
+	var others []v1alpha1.KronosAppObject
+	// holders are the KronosApps no longer claiming objects, which keep those
+	// they hold asleep until they wake them up or release them, so that no
+	// other KronosApp adopts them and records their asleep state as original.
+	holders := make(map[string]v1alpha1.KronosAppObject)
+	for _, other := range kronosApps {
+		if getClaimantName(other) == self {
+			continue
+		}
+		if !isClaiming(other) {
+			holders[getClaimantName(other)] = other
+			continue
+		}
+		_, err := ValidateIncludedObjects(other.GetSpec().IncludedObjects)
+		if err != nil {
+			l.Error(err, "Validating Included Objects", "kronosapp", getClaimantName(other))
+			continue
+		}
+		others = append(others, other)
+	}
+	namespaces := make(map[string]*corev1.Namespace)
+	if len(others) != 0 {
+		namespaceList := &corev1.NamespaceList{}
+		err = Client.List(ctx, namespaceList)
+		if err != nil {
+			return nil, err
+		}
+		for index := range namespaceList.Items {
+			namespaces[namespaceList.Items[index].Name] = &namespaceList.Items[index]
+		}
+	}
+
+	claims := make(map[string][]claimant)
+	includedObjects.forEachObject(func(kind string, item metav1.Object) {
+		key := getObjectKey(kind, item)
+		claims[key] = append(claims[key], newClaimant(kronosApp))
+		if holder, ok := holders[object.GetOwner(item)]; ok {
+			claims[key] = append(claims[key], newClaimant(holder))
+		}
+		for _, other := range others {
+			for _, includedObject := range other.GetSpec().IncludedObjects {
+				if includesObject(includedObject, kind, item, namespaces[item.GetNamespace()]) {
+					claims[key] = append(claims[key], newClaimant(other))
+					break
+				}
+			}
+		}
+	})
+	return claims, nil
+}
+
+// electOwner picks the claimant handling an object included by several of
+// them: the one holding it asleep as long as it still includes it, then
+// KronosApps before ClusterKronosApps, then the oldest one, the name breaking
+// ties.
+func electOwner(claimants []claimant, item metav1.Object) string {
+	currentOwner := object.GetOwner(item)
+	for _, claim := range claimants {
+		if claim.name == currentOwner {
+			return currentOwner
+		}
+	}
+	sorted := append([]claimant(nil), claimants...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].clusterScoped != sorted[j].clusterScoped {
+			return !sorted[i].clusterScoped
+		}
+		if !sorted[i].creationTimestamp.Equal(&sorted[j].creationTimestamp) {
+			return sorted[i].creationTimestamp.Before(&sorted[j].creationTimestamp)
+		}
+		return sorted[i].name < sorted[j].name
+	})
+	return sorted[0].name
+}
+
+// arbitrateClaims leaves out the included objects handled by another
+// KronosApp and records every object shared with other KronosApps as a
+// conflict.
+func arbitrateClaims(kronosApp v1alpha1.KronosAppObject, includedObjects *ObjectList, claims map[string][]claimant) {
+	self := getClaimantName(kronosApp)
+	includedObjects.Owner = self
+	includedObjects.filterObjects(func(kind string, item metav1.Object) string {
+		claimants := claims[getObjectKey(kind, item)]
+		if len(claimants) < 2 {
+			return ""
+		}
+		owner := electOwner(claimants, item)
+		var names []string
+		for _, claim := range claimants {
+			names = append(names, claim.name)
+		}
+		includedObjects.Conflicts = append(includedObjects.Conflicts, v1alpha1.ResourceConflict{
+			Kind:      kind,
+			Namespace: item.GetNamespace(),
+			Name:      item.GetName(),
+			Owner:     owner,
+			Claimants: names,
+		})
+		if owner == self {
+			return ""
+		}
+		return fmt.Sprintf("claimed by %s", owner)
+	})
+}
@@ -0,0 +1,192 @@
+package kronosapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestDeployment(name, owner string) appsv1.Deployment {
+	deployment := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	if owner != "" {
+		deployment.Annotations = map[string]string{object.OwnerAnnotation: owner}
+	}
+	return deployment
+}
+
+func TestElectOwner(t *testing.T) {
+	older := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name      string
+		claimants []claimant
+		owner     string
+		expected  string
+	}{
+		{
+			name: "current owner kept",
+			claimants: []claimant{
+				{name: "KronosApp default/a", creationTimestamp: older},
+				{name: "ClusterKronosApp b", clusterScoped: true, creationTimestamp: newer},
+			},
+			owner:    "ClusterKronosApp b",
+			expected: "ClusterKronosApp b",
+		},
+		{
+			name: "owner no longer claiming ignored",
+			claimants: []claimant{
+				{name: "KronosApp default/b", creationTimestamp: newer},
+				{name: "KronosApp default/a", creationTimestamp: older},
+			},
+			owner:    "KronosApp default/c",
+			expected: "KronosApp default/a",
+		},
+		{
+			name: "namespaced before cluster",
+			claimants: []claimant{
+				{name: "ClusterKronosApp a", clusterScoped: true, creationTimestamp: older},
+				{name: "KronosApp default/b", creationTimestamp: newer},
+			},
+			expected: "KronosApp default/b",
+		},
+		{
+			name: "oldest first",
+			claimants: []claimant{
+				{name: "KronosApp default/a", creationTimestamp: newer},
+				{name: "KronosApp default/b", creationTimestamp: older},
+			},
+			expected: "KronosApp default/b",
+		},
+		{
+			name: "name breaks ties",
+			claimants: []claimant{
+				{name: "KronosApp default/b", creationTimestamp: older},
+				{name: "KronosApp default/a", creationTimestamp: older},
+			},
+			expected: "KronosApp default/a",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deployment := newTestDeployment("web", test.owner)
+			owner := electOwner(test.claimants, &deployment)
+			if owner != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, owner)
+			}
+		})
+	}
+}
+
+func TestArbitrateClaims(t *testing.T) {
+	older := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	self := &v1alpha1.KronosApp{ObjectMeta: metav1.ObjectMeta{Name: "self", Namespace: "default", CreationTimestamp: newer}}
+	selfClaim := claimant{name: "KronosApp default/self", creationTimestamp: newer}
+	olderClaim := claimant{name: "KronosApp default/older", creationTimestamp: older}
+	clusterClaim := claimant{name: "ClusterKronosApp cluster", clusterScoped: true, creationTimestamp: older}
+
+	includedObjects := ObjectList{
+		Deployments: &appsv1.DeploymentList{Items: []appsv1.Deployment{
+			newTestDeployment("alone", ""),
+			newTestDeployment("lost", ""),
+			newTestDeployment("won", ""),
+			newTestDeployment("held", "KronosApp default/self"),
+		}},
+	}
+	claims := map[string][]claimant{
+		"Deployment/default/alone": {selfClaim},
+		"Deployment/default/lost":  {selfClaim, olderClaim},
+		"Deployment/default/won":   {selfClaim, clusterClaim},
+		"Deployment/default/held":  {selfClaim, olderClaim},
+	}
+	arbitrateClaims(self, &includedObjects, claims)
+
+	if includedObjects.Owner != "KronosApp default/self" {
+		t.Errorf("expected the owner to be recorded, got %q", includedObjects.Owner)
+	}
+	var kept []string
+	for _, deployment := range includedObjects.Deployments.Items {
+		kept = append(kept, deployment.Name)
+	}
+	if len(kept) != 3 || kept[0] != "alone" || kept[1] != "won" || kept[2] != "held" {
+		t.Errorf("expected alone, won and held to be kept, got %v", kept)
+	}
+	if len(includedObjects.Skipped) != 1 || includedObjects.Skipped[0].Name != "lost" || includedObjects.Skipped[0].Reason != "claimed by KronosApp default/older" {
+		t.Errorf("expected lost to be skipped for the older KronosApp, got %+v", includedObjects.Skipped)
+	}
+	if len(includedObjects.Conflicts) != 3 {
+		t.Fatalf("expected 3 conflicts, got %+v", includedObjects.Conflicts)
+	}
+	owners := make(map[string]string)
+	for _, conflict := range includedObjects.Conflicts {
+		owners[conflict.Name] = conflict.Owner
+		if len(conflict.Claimants) != 2 {
+			t.Errorf("expected 2 claimants for %s, got %v", conflict.Name, conflict.Claimants)
+		}
+	}
+	expected := map[string]string{
+		"lost": "KronosApp default/older",
+		"won":  "KronosApp default/self",
+		"held": "KronosApp default/self",
+	}
+	for name, owner := range expected {
+		if owners[name] != owner {
+			t.Errorf("expected %s to be owned by %q, got %q", name, owner, owners[name])
+		}
+	}
+}
+
+func TestGetClaimsHandover(t *testing.T) {
+	older := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	includedObjects := []v1alpha1.IncludedObject{{ApiVersion: "apps/v1", Kind: "Deployment", Namespace: "default"}}
+	newKronosApp := func(name string, creationTimestamp metav1.Time) *v1alpha1.KronosApp {
+		return &v1alpha1.KronosApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: creationTimestamp},
+			Spec:       v1alpha1.KronosAppSpec{IncludedObjects: includedObjects},
+		}
+	}
+	self := newKronosApp("self", newer)
+	suspended := newKronosApp("suspended", older)
+	suspended.Spec.Suspend = true
+	deleting := newKronosApp("deleting", older)
+	deleting.Finalizers = []string{finalizerName}
+	deleting.DeletionTimestamp = &newer
+
+	tests := []struct {
+		name    string
+		holder  *v1alpha1.KronosApp
+		owner   string
+		skipped string
+	}{
+		{name: "held by a suspended KronosApp", holder: suspended, owner: "KronosApp default/suspended", skipped: "claimed by KronosApp default/suspended"},
+		{name: "held by a KronosApp being deleted", holder: deleting, owner: "KronosApp default/deleting", skipped: "claimed by KronosApp default/deleting"},
+		{name: "not held by a suspended KronosApp", holder: suspended},
+		{name: "released by a suspended KronosApp", holder: suspended, owner: "KronosApp default/gone"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Client := newTestClient(t, self.DeepCopy(), test.holder.DeepCopy())
+			objectList := ObjectList{Deployments: &appsv1.DeploymentList{Items: []appsv1.Deployment{newTestDeployment("web", test.owner)}}}
+			claims, err := getClaims(context.Background(), Client, self, &objectList)
+			if err != nil {
+				t.Fatal(err)
+			}
+			arbitrateClaims(self, &objectList, claims)
+			if test.skipped == "" {
+				if len(objectList.Skipped) != 0 || objectList.Deployments == nil {
+					t.Errorf("expected the Deployment to be adopted, got %+v", objectList.Skipped)
+				}
+				return
+			}
+			if objectList.Deployments != nil || len(objectList.Skipped) != 1 || objectList.Skipped[0].Reason != test.skipped {
+				t.Errorf("expected the Deployment to be skipped as %q, got %+v", test.skipped, objectList.Skipped)
+			}
+		})
+	}
+}
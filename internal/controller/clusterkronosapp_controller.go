@@ -0,0 +1,102 @@
+/*
+Copyright 2024 IsmailAbdelkefi.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kronosapp
+
+import (
+	"context"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ClusterKronosAppReconciler reconciles a ClusterKronosApp object
+type ClusterKronosAppReconciler struct {
+	KronosAppReconciler
+	// StateNamespace is the namespace of the Secrets or ConfigMaps recording
+	// the objects put to sleep by ClusterKronosApps.
+	StateNamespace string
+}
+
+//+kubebuilder:rbac:groups=core.wecraft.tn,resources=clusterkronosapps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core.wecraft.tn,resources=clusterkronosapps/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core.wecraft.tn,resources=clusterkronosapps/finalizers,verbs=update
+
+// Reconcile applies the schedule of a ClusterKronosApp the same way as for a
+// KronosApp. Namespaced KronosApps take precedence: the objects they include
+// are skipped by ClusterKronosApps, unless held asleep by one of them.
+func (r *ClusterKronosAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.Log
+	clusterKronosApp := &v1alpha1.ClusterKronosApp{}
+	err := r.Get(ctx, req.NamespacedName, clusterKronosApp)
+	if err != nil {
+		l.Error(err, "Unable to fetch ClusterKronosApp")
+		if apierrors.IsNotFound(err) {
+			r.deleteMetrics(req)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	return r.reconcileKronosApp(ctx, req, clusterKronosApp, getClusterSecretName(req.Name), r.StateNamespace)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterKronosAppReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	pred := predicate.GenerationChangedPredicate{}
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ClusterKronosApp{}, builder.WithPredicates(pred))
+	if document := r.getOwnedStateDocument(); document != nil {
+		b = b.Owns(document, builder.WithPredicates(stateDocumentPredicate()))
+	}
+	b = b.Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.findClusterKronosAppsForNamespace), builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(&v1alpha1.KronosApp{}, handler.EnqueueRequestsFromMapFunc(r.findOverlappingClusterKronosApps), builder.WithPredicates(pred)).
+		Watches(&v1alpha1.ClusterKronosApp{}, handler.EnqueueRequestsFromMapFunc(r.findOverlappingClusterKronosApps), builder.WithPredicates(pred))
+	return watchManagedObjects(b, mgr.GetRESTMapper(), "ClusterKronosApp", r.findClusterKronosAppsForObject).
+		Complete(r)
+}
+
+// findOverlappingClusterKronosApps enqueues the ClusterKronosApps that may
+// include the same objects as a changed KronosApp or ClusterKronosApp, as the
+// change may release or claim objects they include.
+func (r *ClusterKronosAppReconciler) findOverlappingClusterKronosApps(ctx context.Context, changed client.Object) []reconcile.Request {
+	l := log.FromContext(ctx)
+	changedKronosApp, ok := changed.(v1alpha1.KronosAppObject)
+	if !ok {
+		return nil
+	}
+	clusterKronosApps := &v1alpha1.ClusterKronosAppList{}
+	err := r.List(ctx, clusterKronosApps)
+	if err != nil {
+		l.Error(err, "Listing ClusterKronosApps")
+		return nil
+	}
+	var requests []reconcile.Request
+	for index := range clusterKronosApps.Items {
+		clusterKronosApp := &clusterKronosApps.Items[index]
+		if getClaimantName(clusterKronosApp) == getClaimantName(changedKronosApp) || !clusterKronosApp.Spec.MayOverlap(*changedKronosApp.GetSpec()) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(clusterKronosApp)})
+	}
+	return requests
+}
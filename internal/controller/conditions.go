@@ -0,0 +1,47 @@
+package kronosapp
+
+import (
+	"context"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newCondition(conditionType string, status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// setStatusConditions records conditions observed on the given generation of
+// a KronosApp, keeping the transition time of those left unchanged.
+func setStatusConditions(status *v1alpha1.KronosAppStatus, generation int64, conditions ...metav1.Condition) {
+	for _, condition := range conditions {
+		condition.ObservedGeneration = generation
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}
+	status.ObservedGeneration = generation
+}
+
+// setConditions records conditions on the status of a KronosApp whose
+// reconciliation stops early, the rest of its status being left as it is.
+func (r *KronosAppReconciler) setConditions(ctx context.Context, kronosApp v1alpha1.KronosAppObject, conditions ...metav1.Condition) {
+	l := log.Log
+	newStatus := *kronosApp.GetStatus().DeepCopy()
+	setStatusConditions(&newStatus, kronosApp.GetGeneration(), conditions...)
+	err := v1alpha1.SetNewKronosAppStatus(ctx, r.Client, kronosApp, newStatus)
+	if err != nil {
+		l.Error(err, "Updating KronosApp Conditions")
+	}
+}
+
+// notReady returns the Ready condition of a KronosApp whose reconciliation
+// failed for the given reason.
+func notReady(reason string, err error) metav1.Condition {
+	return newCondition(v1alpha1.ConditionReady, metav1.ConditionFalse, reason, err.Error())
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 IsmailAbdelkefi.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kronosapp
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	corewecrafttnv1alpha1 "github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+)
+
+func newCronJob(name string, suspend *bool) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "*/5 * * * *",
+			Suspend:  suspend,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{{
+								Name:  "job",
+								Image: "busybox",
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("CronJob sleep", func() {
+	ctx := context.Background()
+	secretName := getSecretName("cronjob-test")
+	cronjobs := map[string]*bool{
+		"cronjob-unset":     nil,
+		"cronjob-false":     ptr.To(false),
+		"cronjob-suspended": ptr.To(true),
+	}
+	originalSuspend := map[string]bool{
+		"cronjob-unset":     false,
+		"cronjob-false":     false,
+		"cronjob-suspended": true,
+	}
+
+	getSuspend := func(name string) *bool {
+		cronjob := &batchv1.CronJob{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, cronjob)).To(Succeed())
+		return cronjob.Spec.Suspend
+	}
+
+	BeforeEach(func() {
+		By("creating the CronJobs and the KronosApp secret")
+		for name, suspend := range cronjobs {
+			Expect(k8sClient.Create(ctx, newCronJob(name, suspend))).To(Succeed())
+		}
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"}}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for name := range cronjobs {
+			Expect(k8sClient.Delete(ctx, &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}})).To(Succeed())
+		}
+		Expect(k8sClient.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"}})).To(Succeed())
+	})
+
+	It("should suspend CronJobs and restore their original suspend value", func() {
+		includedObjects := []corewecrafttnv1alpha1.IncludedObject{{
+			ApiVersion: "batch/v1",
+			Kind:       "CronJob",
+			Namespace:  "default",
+			IncludeRef: "^cronjob-",
+			ExcludeRef: "^$",
+		}}
+		inclusive, err := ValidateIncludedObjects(includedObjects)
+		Expect(err).NotTo(HaveOccurred())
+		objectList, err := FetchIncludedObjects(ctx, k8sClient, includedObjects, inclusive)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objectList.GetObjectsTotalCount()).To(Equal(len(cronjobs)))
+
+		By("putting the CronJobs to sleep")
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+		t, err := putIncludedObjectsToSleep(ctx, k8sClient, newDocumentStore(k8sClient, secret), objectList, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.failed).To(BeEmpty())
+		for name := range cronjobs {
+			Expect(getSuspend(name)).To(Equal(ptr.To(true)), name)
+		}
+
+		By("recording the original suspend values")
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+		var document stateDocument
+		Expect(json.Unmarshal(secret.Data["CronJob"], &document)).To(Succeed())
+		Expect(document.Version).To(Equal(StateVersion))
+		Expect(document.Kind).To(Equal("CronJob"))
+		var records []object.StatusResource
+		Expect(json.Unmarshal(document.Resources, &records)).To(Succeed())
+		Expect(records).To(HaveLen(len(cronjobs)))
+		for _, record := range records {
+			Expect(record.ResourceUID).NotTo(BeEmpty())
+			Expect(record.ResourceStatus).NotTo(BeNil())
+			Expect(*record.ResourceStatus).To(Equal(originalSuspend[record.ResourceName]), record.ResourceName)
+		}
+
+		By("waking the CronJobs up")
+		Expect(WakeUpResources(ctx, k8sClient, newDocumentStore(k8sClient, secret))).To(Succeed())
+		for name, suspend := range originalSuspend {
+			Expect(getSuspend(name)).To(Equal(ptr.To(suspend)), name)
+		}
+	})
+})
@@ -0,0 +1,80 @@
+package kronosapp
+
+import (
+	"context"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isDryRun reports whether the controller only computes what it would do for
+// a KronosApp, leaving its included objects and its state store untouched.
+func (r *KronosAppReconciler) isDryRun(kronosApp v1alpha1.KronosAppObject) bool {
+	return r.DryRun || kronosApp.GetSpec().DryRun
+}
+
+// getObjectClient returns the client used to change the included objects of a
+// KronosApp. In dry run, the changes are sent with the dryRun option, so that
+// the API server validates them without persisting them.
+func (r *KronosAppReconciler) getObjectClient(kronosApp v1alpha1.KronosAppObject) client.Client {
+	if r.isDryRun(kronosApp) {
+		return client.NewDryRunClient(r.Client)
+	}
+	return r.Client
+}
+
+// dryRunStore keeps the records in memory, on top of those of the state store
+// of the KronosApp, if any, which is never written.
+type dryRunStore struct {
+	store   StateStore
+	records map[string][]object.ResourceInt
+}
+
+func newDryRunStore(store StateStore) *dryRunStore {
+	return &dryRunStore{
+		store:   store,
+		records: make(map[string][]object.ResourceInt),
+	}
+}
+
+func (s *dryRunStore) Load(ctx context.Context, kind string) ([]object.ResourceInt, error) {
+	if resources, ok := s.records[kind]; ok || s.store == nil {
+		return resources, nil
+	}
+	return s.store.Load(ctx, kind)
+}
+
+func (s *dryRunStore) Save(ctx context.Context, kind string, resources []object.ResourceInt) error {
+	s.records[kind] = resources
+	return nil
+}
+
+func (s *dryRunStore) Purge(ctx context.Context) error {
+	for _, kind := range getAllKinds() {
+		s.records[kind] = nil
+	}
+	return nil
+}
+
+func (s *dryRunStore) Delete(ctx context.Context) error {
+	return s.Purge(ctx)
+}
+
+func (s *dryRunStore) Verify() error {
+	if s.store == nil {
+		return nil
+	}
+	return s.store.Verify()
+}
+
+// openDryRunStateStore returns the store of a KronosApp in dry run, reading
+// the records of its state store when it exists but never creating, migrating
+// or writing it.
+func (r *KronosAppReconciler) openDryRunStateStore(ctx context.Context, kronosApp v1alpha1.KronosAppObject, name, namespace string) (StateStore, error) {
+	store, err := r.getStateStore(ctx, r.getSelectedStateStore(), kronosApp, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return newDryRunStore(store), nil
+}
@@ -0,0 +1,82 @@
+package kronosapp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileDryRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		dryRun   bool
+		expected int32
+	}{
+		{name: "applied", expected: 0},
+		{name: "dry run", dryRun: true, expected: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			kronosApp := &v1alpha1.KronosApp{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec: v1alpha1.KronosAppSpec{
+					StartSleep:      "00:00",
+					EndSleep:        "00:00",
+					WeekDays:        "1-5",
+					ForceSleep:      true,
+					DryRun:          test.dryRun,
+					IncludedObjects: []v1alpha1.IncludedObject{{ApiVersion: "apps/v1", Kind: "Deployment", Namespace: "default"}},
+				},
+			}
+			web := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(3))},
+			}
+			Client := newTestClient(t, kronosApp, web)
+			r := &KronosAppReconciler{Client: Client, Scheme: Client.Scheme()}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+			for i := 0; i < 2; i++ {
+				_, err := r.Reconcile(ctx, req)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := Client.Get(ctx, client.ObjectKeyFromObject(web), web)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *web.Spec.Replicas != test.expected {
+				t.Errorf("expected the Deployment to have %d replicas, got %d", test.expected, *web.Spec.Replicas)
+			}
+			if _, ok := web.Annotations[object.OwnerAnnotation]; ok == test.dryRun {
+				t.Errorf("expected the owner annotation to be set %v, got %v", !test.dryRun, web.Annotations)
+			}
+			err = Client.Get(ctx, types.NamespacedName{Name: getSecretName("app"), Namespace: "default"}, &corev1.Secret{})
+			if apierrors.IsNotFound(err) != test.dryRun {
+				t.Errorf("expected the state store to exist %v, got %v", !test.dryRun, err)
+			}
+			err = Client.Get(ctx, req.NamespacedName, kronosApp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kronosApp.Status.DryRun != test.dryRun {
+				t.Errorf("expected the status to report dry run %v, got %v", test.dryRun, kronosApp.Status.DryRun)
+			}
+			if len(kronosApp.Status.Resources) != 1 || kronosApp.Status.Resources[0].State != v1alpha1.ResourceStateAsleep {
+				t.Errorf("expected the Deployment to be reported asleep, got %+v", kronosApp.Status.Resources)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package kronosapp
+
+import (
+	"fmt"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The reasons of the Events recorded on KronosApps.
+const (
+	ReasonSleeping           = "Sleeping"
+	ReasonAsleep             = "Asleep"
+	ReasonWaking             = "Waking"
+	ReasonAwake              = "Awake"
+	ReasonTransitionFailed   = "TransitionFailed"
+	ReasonPartialFailure     = "PartialFailure"
+	ReasonHolidayStarted     = "HolidayStarted"
+	ReasonHolidayEnded       = "HolidayEnded"
+	ReasonOverrideActive     = "OverrideActive"
+	ReasonOverrideEnded      = "OverrideEnded"
+	ReasonDriftDetected      = "DriftDetected"
+	ReasonStateStoreMissing  = "StateStoreMissing"
+	ReasonStateStoreTampered = "StateStoreTampered"
+	ReasonStateMigrated      = "StateMigrated"
+	ReasonSuspended          = "Suspended"
+	ReasonResumed            = "Resumed"
+)
+
+// recordEvent records an Event on a KronosApp, when the reconciler was given
+// a recorder.
+func (r *KronosAppReconciler) recordEvent(kronosApp v1alpha1.KronosAppObject, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	if r.isDryRun(kronosApp) {
+		messageFmt = "Dry run: " + messageFmt
+	}
+	r.Recorder.Eventf(kronosApp, eventType, reason, messageFmt, args...)
+}
+
+// recordResourceEvent records an Event on an object handled by a KronosApp.
+func (r *KronosAppReconciler) recordResourceEvent(resource v1alpha1.ManagedResource, uid types.UID, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	reference := &corev1.ObjectReference{
+		APIVersion: getSupportedObjectsApiVersionAndKind().GetAPIVersion(resource.Kind),
+		Kind:       resource.Kind,
+		Namespace:  resource.Namespace,
+		Name:       resource.Name,
+		UID:        uid,
+	}
+	r.Recorder.Eventf(reference, eventType, reason, messageFmt, args...)
+}
+
+func isOverride(reason string) bool {
+	return reason == "ForceSleep" || reason == "ForceWake" || reason == "WakeOverride"
+}
+
+// recordScheduleEvents records the holidays and overrides a KronosApp enters
+// or leaves.
+func (r *KronosAppReconciler) recordScheduleEvents(kronosApp v1alpha1.KronosAppObject, currentStatus, newStatus v1alpha1.KronosAppStatus) {
+	if currentStatus.Reason == newStatus.Reason {
+		return
+	}
+	switch {
+	case currentStatus.Reason == "Holiday":
+		r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonHolidayEnded, "The holiday is over")
+	case isOverride(currentStatus.Reason):
+		r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonOverrideEnded, "%s is no longer in effect", currentStatus.Reason)
+	case currentStatus.Status == v1alpha1.StatusSuspended:
+		r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonResumed, "The schedule is applied again")
+	}
+	switch {
+	case newStatus.Reason == "Holiday":
+		r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonHolidayStarted, "Asleep for a holiday until %s", newStatus.NextOperation)
+	case isOverride(newStatus.Reason):
+		r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonOverrideActive, "%s is in effect", newStatus.Reason)
+	}
+}
+
+// recordTransitionStarted records the start of a transition of a KronosApp
+// going to sleep or waking up, isTimeToSleep telling which.
+func (r *KronosAppReconciler) recordTransitionStarted(kronosApp v1alpha1.KronosAppObject, currentStatus v1alpha1.KronosAppStatus, isTimeToSleep bool) {
+	if meta.IsStatusConditionTrue(currentStatus.Conditions, v1alpha1.ConditionAsleep) == isTimeToSleep {
+		return
+	}
+	if isTimeToSleep {
+		r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonSleeping, "Putting the included objects to sleep")
+	} else {
+		r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonWaking, "Waking up the included objects")
+	}
+}
+
+// recordTransitionEvents records the outcome of a reconciliation on the
+// KronosApp and on each object it acted on.
+func (r *KronosAppReconciler) recordTransitionEvents(kronosApp v1alpha1.KronosAppObject, currentStatus v1alpha1.KronosAppStatus, isTimeToSleep bool, t *transition, err error) {
+	owner := getClaimantName(kronosApp)
+	if r.isDryRun(kronosApp) {
+		owner += " in dry run"
+	}
+	for _, resource := range t.resources {
+		if resource.LastAction == "" {
+			continue
+		}
+		uid := t.uids[getManagedResourceKey(resource)]
+		if resource.LastError != "" {
+			r.recordResourceEvent(resource, uid, corev1.EventTypeWarning, resource.LastAction+"Failed", "%s failed for %s: %s", resource.LastAction, owner, resource.LastError)
+		} else {
+			r.recordResourceEvent(resource, uid, corev1.EventTypeNormal, resource.LastAction, "%s done by %s", resource.LastAction, owner)
+		}
+	}
+	switch {
+	case err != nil:
+		r.recordEvent(kronosApp, corev1.EventTypeWarning, ReasonTransitionFailed, "%s", err.Error())
+	case len(t.failed) != 0:
+		r.recordEvent(kronosApp, corev1.EventTypeWarning, ReasonPartialFailure, "%s", fmt.Sprintf("Some objects could not be handled: %v", t.failed))
+	case meta.IsStatusConditionTrue(currentStatus.Conditions, v1alpha1.ConditionAsleep) != isTimeToSleep:
+		if isTimeToSleep {
+			r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonAsleep, "The included objects are asleep")
+		} else {
+			r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonAwake, "The included objects are awake")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package kronosapp
+
+import (
+	"context"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// finalizerName holds the deletion of a KronosApp until the objects it put
+// to sleep are handled according to its deletion policy.
+const finalizerName = "kronos.wecraft.tn/finalizer"
+
+func (r *KronosAppReconciler) addFinalizer(ctx context.Context, kronosApp v1alpha1.KronosAppObject) error {
+	if controllerutil.ContainsFinalizer(kronosApp, finalizerName) {
+		return nil
+	}
+	controllerutil.AddFinalizer(kronosApp, finalizerName)
+	return r.Update(ctx, kronosApp)
+}
+
+// releaseResources removes the owner annotation of the resources recorded in
+// the store, leaving them asleep.
+func releaseResources(ctx context.Context, Client client.Client, store StateStore) error {
+	resourceList, err := loadAllRecords(ctx, store)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resourceList {
+		err = setResourceOwner(ctx, Client, resource, "")
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// finalizeKronosApp applies the deletion policy of a KronosApp being deleted
+// to the resources recorded in its state store, then deletes the store,
+// clears the metrics and lets the deletion proceed.
+func (r *KronosAppReconciler) finalizeKronosApp(ctx context.Context, req ctrl.Request, kronosApp v1alpha1.KronosAppObject, storeName, storeNamespace string) (ctrl.Result, error) {
+	l := log.Log
+	if !controllerutil.ContainsFinalizer(kronosApp, finalizerName) {
+		return ctrl.Result{}, nil
+	}
+	store, err := r.getStateStore(ctx, r.getSelectedStateStore(), kronosApp, storeName, storeNamespace)
+	if err != nil {
+		l.Error(err, "Fetching State Store")
+		return ctrl.Result{}, err
+	}
+	if store == nil {
+		// The resources still owned by the KronosApp carry their original
+		// state, which is used when its Secret or ConfigMap went missing.
+		store = newAnnotationStore(r.Client, r.getAPIReader(), getClaimantName(kronosApp))
+	}
+	if r.isDryRun(kronosApp) {
+		store = newDryRunStore(store)
+	}
+	objectClient := r.getObjectClient(kronosApp)
+	if kronosApp.GetSpec().DeletionPolicy == v1alpha1.DeletionPolicyLeaveAsleep {
+		err = releaseResources(ctx, objectClient, store)
+		if err != nil {
+			l.Error(err, "Releasing Resources")
+			return ctrl.Result{}, err
+		}
+	} else {
+		err = WakeUpResources(ctx, objectClient, store)
+		if err != nil {
+			l.Error(err, "Waking Up Resources")
+			return ctrl.Result{}, err
+		}
+	}
+	err = store.Delete(ctx)
+	if err != nil {
+		l.Error(err, "Deleting State Store")
+		return ctrl.Result{}, err
+	}
+	r.deleteOldMetrics(req, *kronosApp.GetStatus())
+	r.deleteMetrics(req)
+	controllerutil.RemoveFinalizer(kronosApp, finalizerName)
+	err = r.Update(ctx, kronosApp)
+	if err != nil {
+		l.Error(err, "Removing Finalizer")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,111 @@
+package object
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ExcludeAnnotation set to "true" on an object leaves it out of every
+	// KronosApp including it.
+	ExcludeAnnotation = "kronos.wecraft.tn/exclude"
+	// SleepReplicasAnnotation sets the number of replicas a workload is
+	// scaled to while asleep.
+	SleepReplicasAnnotation = "kronos.wecraft.tn/sleep-replicas"
+	// OwnerAnnotation is set on the objects put to sleep, holding the
+	// KronosApp that recorded their original state. It is removed on wake.
+	OwnerAnnotation = "kronos.wecraft.tn/owner"
+	// StateAnnotation is set along with OwnerAnnotation, holding a copy of
+	// the recorded original state used to recover a lost KronosApp secret.
+	StateAnnotation = "kronos.wecraft.tn/original-state"
+	// OriginalReplicasAnnotation is set along with OwnerAnnotation on the
+	// workloads scaled down, holding their original replicas.
+	OriginalReplicasAnnotation = "kronos.wecraft.tn/original-replicas"
+	// OwnerLabel is set along with OwnerAnnotation, holding a hash of the
+	// KronosApp name, which cannot be a label value, so that the objects it
+	// holds asleep can be listed with a label selector.
+	OwnerLabel = "kronos.wecraft.tn/owner-hash"
+)
+
+// GetOwnerLabel returns the value of OwnerLabel for a KronosApp.
+func GetOwnerLabel(owner string) string {
+	hash := sha256.Sum256([]byte(owner))
+	return hex.EncodeToString(hash[:16])
+}
+
+// IsExcluded reports whether an object opted out of the schedule through the
+// exclude annotation.
+func IsExcluded(item metav1.Object) bool {
+	excluded, err := strconv.ParseBool(item.GetAnnotations()[ExcludeAnnotation])
+	return err == nil && excluded
+}
+
+// GetSleepReplicas returns the number of replicas set by the sleep-replicas
+// annotation of a workload, if any.
+func GetSleepReplicas(item metav1.Object) (int32, bool, error) {
+	value, ok := item.GetAnnotations()[SleepReplicasAnnotation]
+	if !ok {
+		return 0, false, nil
+	}
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || replicas < 0 {
+		return 0, false, fmt.Errorf("invalid %s annotation %q", SleepReplicasAnnotation, value)
+	}
+	return int32(replicas), true, nil
+}
+
+// GetAnnotationsSkipReason returns why an object is left out of the schedule
+// because of its annotations, or an empty string when it is not.
+func GetAnnotationsSkipReason(item metav1.Object) string {
+	if IsExcluded(item) {
+		return fmt.Sprintf("excluded by the %s annotation", ExcludeAnnotation)
+	}
+	_, _, err := GetSleepReplicas(item)
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// GetOwner returns the KronosApp holding an object asleep, if any.
+func GetOwner(item metav1.Object) string {
+	return item.GetAnnotations()[OwnerAnnotation]
+}
+
+// GetState returns the copy of the original state recorded on an object.
+func GetState(item metav1.Object) string {
+	return item.GetAnnotations()[StateAnnotation]
+}
+
+// SetAnnotations merges annotations into those of an object, a nil value
+// removing the annotation.
+func SetAnnotations(ctx context.Context, Client client.Client, apiVersion, kind, namespace, name string, annotations map[string]*string) error {
+	return SetMetadata(ctx, Client, apiVersion, kind, namespace, name, nil, annotations)
+}
+
+// SetMetadata merges labels and annotations into those of an object, a nil
+// value removing the label or annotation.
+func SetMetadata(ctx context.Context, Client client.Client, apiVersion, kind, namespace, name string, labels, annotations map[string]*string) error {
+	resource := Resource{
+		ResourceName:       name,
+		ResourceKind:       kind,
+		ResourceNamespace:  namespace,
+		ResourceApiVersion: apiVersion,
+	}
+	metadata := map[string]interface{}{}
+	if len(labels) != 0 {
+		metadata["labels"] = labels
+	}
+	if len(annotations) != 0 {
+		metadata["annotations"] = annotations
+	}
+	return mergePatch(ctx, Client, resource, map[string]interface{}{
+		"metadata": metadata,
+	})
+}
@@ -5,8 +5,6 @@ import (
 	"regexp"
 
 	batchv1 "k8s.io/api/batch/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func getCronjobsByPattern(object Object, allObjects *batchv1.CronJobList) *batchv1.CronJobList {
@@ -29,7 +27,7 @@ func getCronjobsByPattern(object Object, allObjects *batchv1.CronJobList) *batch
 
 func getAllCronjobs(ctx context.Context, object Object) (*batchv1.CronJobList, error) {
 	cronjobList := &batchv1.CronJobList{}
-	err := object.Client.List(ctx, cronjobList, client.InNamespace(object.Namespace))
+	err := object.Client.List(ctx, cronjobList, object.listOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -44,8 +42,7 @@ func GetCronjobListNames(objects *batchv1.CronJobList) []string {
 	return listNames
 }
 
-func FetchCronjobs(ctx context.Context, Client client.Client, includeRef, excludeRef, namespace string) (*batchv1.CronJobList, error) {
-	resource := NewObject(Client, includeRef, excludeRef, namespace)
+func FetchCronjobs(ctx context.Context, resource Object) (*batchv1.CronJobList, error) {
 	allObjects, err := getAllCronjobs(ctx, resource)
 	if err != nil {
 		return nil, err
@@ -53,13 +50,3 @@ func FetchCronjobs(ctx context.Context, Client client.Client, includeRef, exclud
 	filteredObjects := getCronjobsByPattern(resource, allObjects)
 	return filteredObjects, nil
 }
-
-func GetCronjob(ctx context.Context, Client client.Client, resource StatusResource) (*batchv1.CronJob, error) {
-	cronjob := batchv1.CronJob{}
-	err := Client.Get(ctx, types.NamespacedName{Name: resource.ResourceName, Namespace: resource.ResourceNamespace}, &cronjob)
-	if err != nil {
-		return nil, err
-	}
-	cronjob.Spec.Suspend = resource.ResourceStatus
-	return &cronjob, nil
-}
@@ -5,8 +5,6 @@ import (
 	"regexp"
 
 	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func getDeploymentsByPattern(object Object, allObjects *appsv1.DeploymentList) *appsv1.DeploymentList {
@@ -30,7 +28,7 @@ func getDeploymentsByPattern(object Object, allObjects *appsv1.DeploymentList) *
 
 func getAllDeployments(ctx context.Context, object Object) (*appsv1.DeploymentList, error) {
 	deploymentList := &appsv1.DeploymentList{}
-	err := object.Client.List(ctx, deploymentList, client.InNamespace(object.Namespace))
+	err := object.Client.List(ctx, deploymentList, object.listOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -45,8 +43,7 @@ func GetDeploymentListNames(objects *appsv1.DeploymentList) []string {
 	return listNames
 }
 
-func FetchDeployments(ctx context.Context, Client client.Client, includeRef, excludeRef, namespace string) (*appsv1.DeploymentList, error) {
-	resource := NewObject(Client, includeRef, excludeRef, namespace)
+func FetchDeployments(ctx context.Context, resource Object) (*appsv1.DeploymentList, error) {
 	allObjects, err := getAllDeployments(ctx, resource)
 	if err != nil {
 		return nil, err
@@ -54,13 +51,3 @@ func FetchDeployments(ctx context.Context, Client client.Client, includeRef, exc
 	filteredObjects := getDeploymentsByPattern(resource, allObjects)
 	return filteredObjects, nil
 }
-
-func GetDeployment(ctx context.Context, Client client.Client, resource ReplicaResource) (*appsv1.Deployment, error) {
-	deployment := appsv1.Deployment{}
-	err := Client.Get(ctx, types.NamespacedName{Name: resource.ResourceName, Namespace: resource.ResourceNamespace}, &deployment)
-	if err != nil {
-		return nil, err
-	}
-	deployment.Spec.Replicas = &resource.ResourceReplicas
-	return &deployment, nil
-}
@@ -0,0 +1,92 @@
+package object
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SyncPolicyResource records the automated sync policy of an Argo CD
+// Application so that self-heal can be disabled while its workloads sleep.
+type SyncPolicyResource struct {
+	Resource
+	ResourceAutomated map[string]interface{} `json:"automated"`
+}
+
+func NewSyncPolicyResource(resourceApiVersion, resourceKind, resourceName, resourceNamespace string, resourceAutomated map[string]interface{}) SyncPolicyResource {
+	return SyncPolicyResource{
+		Resource: Resource{
+			ResourceName:       resourceName,
+			ResourceKind:       resourceKind,
+			ResourceNamespace:  resourceNamespace,
+			ResourceApiVersion: resourceApiVersion,
+		},
+		ResourceAutomated: resourceAutomated,
+	}
+}
+
+func NewSyncPolicyResourceFromUnstructured(item unstructured.Unstructured) SyncPolicyResource {
+	automated, _, _ := unstructured.NestedMap(item.Object, "spec", "syncPolicy", "automated")
+	return NewSyncPolicyResource(item.GetAPIVersion(), item.GetKind(), item.GetName(), item.GetNamespace(), automated)
+}
+
+func (o SyncPolicyResource) GetName() string {
+	return o.ResourceName
+}
+
+func (o SyncPolicyResource) GetNamespace() string {
+	return o.ResourceNamespace
+}
+
+// UpdateClient sets the automated sync policy of the Application to the
+// recorded one, removing it when there is none.
+func (o SyncPolicyResource) UpdateClient(ctx context.Context, Client client.Client) error {
+	var automated interface{}
+	if o.ResourceAutomated != nil {
+		automated = o.ResourceAutomated
+	}
+	return mergePatch(ctx, Client, o.Resource, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"syncPolicy": map[string]interface{}{
+				"automated": automated,
+			},
+		},
+	})
+}
+
+func (o SyncPolicyResource) IsAsleep() bool {
+	return o.ResourceAutomated == nil
+}
+
+func (o SyncPolicyResource) PutToSleep(ctx context.Context, Client client.Client) error {
+	if o.ResourceAutomated != nil {
+		o.ResourceAutomated = nil
+		return o.UpdateClient(ctx, Client)
+	}
+	return nil
+}
+
+func (o SyncPolicyResource) Wake(ctx context.Context, Client client.Client) error {
+	if o.ResourceAutomated == nil {
+		return nil
+	}
+	return o.UpdateClient(ctx, Client)
+}
+
+func CastSyncPolicyToGeneral(resource []SyncPolicyResource) []ResourceInt {
+	var general []ResourceInt
+	for _, item := range resource {
+		general = append(general, item)
+	}
+	return general
+}
+
+// NewStatusResourceFromUnstructured records the spec.suspend field of a Flux
+// Kustomization or HelmRelease.
+func NewStatusResourceFromUnstructured(item unstructured.Unstructured) StatusResource {
+	suspended, _, _ := unstructured.NestedBool(item.Object, "spec", "suspend")
+	resource := NewStatusResource(item.GetKind(), item.GetName(), item.GetNamespace(), suspended)
+	resource.ResourceApiVersion = item.GetAPIVersion()
+	return resource
+}
@@ -0,0 +1,172 @@
+package object
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WakeTimeAnnotation is set on the Ingresses redirected to the sleeping
+// Service, holding the time at which their original backends are restored.
+const WakeTimeAnnotation = "kronos.wecraft.tn/wake-time"
+
+func getIngressesByPattern(object Object, allObjects *networkingv1.IngressList) *networkingv1.IngressList {
+	filteredIngresses := &networkingv1.IngressList{}
+	if object.IncludeRef == "" && object.ExcludeRef == "" {
+		return allObjects
+	}
+	if object.IncludeRef == object.ExcludeRef {
+		return nil
+	}
+
+	includeRe := regexp.MustCompile(object.IncludeRef)
+	excludeRe := regexp.MustCompile(object.ExcludeRef)
+	for _, ingress := range allObjects.Items {
+		if includeRe.MatchString(ingress.Name) && !excludeRe.MatchString(ingress.Name) {
+			filteredIngresses.Items = append(filteredIngresses.Items, ingress)
+		}
+	}
+	return filteredIngresses
+}
+
+func getAllIngresses(ctx context.Context, object Object) (*networkingv1.IngressList, error) {
+	ingressList := &networkingv1.IngressList{}
+	err := object.Client.List(ctx, ingressList, object.listOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return ingressList, err
+}
+
+func GetIngressListNames(objects *networkingv1.IngressList) []string {
+	var listNames []string
+	for _, objectItem := range objects.Items {
+		listNames = append(listNames, objectItem.Name)
+	}
+	return listNames
+}
+
+func FetchIngresses(ctx context.Context, resource Object) (*networkingv1.IngressList, error) {
+	allObjects, err := getAllIngresses(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	filteredObjects := getIngressesByPattern(resource, allObjects)
+	return filteredObjects, nil
+}
+
+// IngressResource records the original backends of an Ingress so that they
+// can be restored once the Ingress no longer points to the sleeping Service.
+type IngressResource struct {
+	Resource
+	ResourceDefaultBackend *networkingv1.IngressBackend `json:"defaultBackend,omitempty"`
+	ResourceRules          []networkingv1.IngressRule   `json:"rules,omitempty"`
+	SleepingBackend        *networkingv1.IngressBackend `json:"-"`
+	WakeTime               string                       `json:"-"`
+}
+
+func NewIngressResource(ingress networkingv1.Ingress, sleepingBackend *networkingv1.IngressBackend, wakeTime string) IngressResource {
+	return IngressResource{
+		Resource: Resource{
+			ResourceName:      ingress.Name,
+			ResourceKind:      "Ingress",
+			ResourceNamespace: ingress.Namespace,
+		},
+		ResourceDefaultBackend: ingress.Spec.DefaultBackend,
+		ResourceRules:          ingress.Spec.Rules,
+		SleepingBackend:        sleepingBackend,
+		WakeTime:               wakeTime,
+	}
+}
+
+func (o IngressResource) GetName() string {
+	return o.ResourceName
+}
+
+func (o IngressResource) GetNamespace() string {
+	return o.ResourceNamespace
+}
+
+// IsAsleep reports whether every backend of the recorded Ingress already
+// points to the sleeping Service, Ingresses being left untouched when there
+// is none.
+func (o IngressResource) IsAsleep() bool {
+	if o.SleepingBackend == nil {
+		return true
+	}
+	if o.ResourceDefaultBackend != nil && !reflect.DeepEqual(o.ResourceDefaultBackend, o.SleepingBackend) {
+		return false
+	}
+	for _, rule := range o.ResourceRules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if !reflect.DeepEqual(&path.Backend, o.SleepingBackend) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// UpdateClient sets the backends of the Ingress to the recorded ones, along
+// with the wake time annotation shown by the sleeping Service.
+func (o IngressResource) UpdateClient(ctx context.Context, Client client.Client) error {
+	var wakeTime *string
+	if o.WakeTime != "" {
+		wakeTime = &o.WakeTime
+	}
+	return mergePatch(ctx, Client, o.Resource, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{
+				WakeTimeAnnotation: wakeTime,
+			},
+		},
+		"spec": map[string]interface{}{
+			"defaultBackend": o.ResourceDefaultBackend,
+			"rules":          o.ResourceRules,
+		},
+	})
+}
+
+func (o IngressResource) PutToSleep(ctx context.Context, Client client.Client) error {
+	if o.IsAsleep() {
+		return nil
+	}
+	if o.ResourceDefaultBackend != nil {
+		o.ResourceDefaultBackend = o.SleepingBackend.DeepCopy()
+	}
+	var rules []networkingv1.IngressRule
+	for _, rule := range o.ResourceRules {
+		sleepingRule := *rule.DeepCopy()
+		if sleepingRule.HTTP != nil {
+			for index := range sleepingRule.HTTP.Paths {
+				sleepingRule.HTTP.Paths[index].Backend = *o.SleepingBackend.DeepCopy()
+			}
+		}
+		rules = append(rules, sleepingRule)
+	}
+	o.ResourceRules = rules
+	return o.UpdateClient(ctx, Client)
+}
+
+func (o IngressResource) Wake(ctx context.Context, Client client.Client) error {
+	o.WakeTime = ""
+	err := o.UpdateClient(ctx, Client)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func CastIngressToGeneral(resource []IngressResource) []ResourceInt {
+	var general []ResourceInt
+	for _, item := range resource {
+		general = append(general, item)
+	}
+	return general
+}
@@ -0,0 +1,111 @@
+package object
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	KedaPausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+	KedaPausedAnnotation         = "autoscaling.keda.sh/paused"
+)
+
+// AnnotationResource records the values a set of annotations had before they
+// were overwritten to put an object to sleep. A nil value means that the
+// annotation was not set and has to be removed on wake.
+type AnnotationResource struct {
+	Resource
+	ResourceAnnotations map[string]*string `json:"annotations"`
+}
+
+func NewAnnotationResource(resourceApiVersion, resourceKind, resourceName, resourceNamespace string, resourceAnnotations map[string]*string) AnnotationResource {
+	return AnnotationResource{
+		Resource: Resource{
+			ResourceName:       resourceName,
+			ResourceKind:       resourceKind,
+			ResourceNamespace:  resourceNamespace,
+			ResourceApiVersion: resourceApiVersion,
+		},
+		ResourceAnnotations: resourceAnnotations,
+	}
+}
+
+// getKedaSleepAnnotations returns the annotations making KEDA stop scaling
+// the given kind: a ScaledObject is pinned to zero replicas while a ScaledJob
+// stops creating jobs.
+func getKedaSleepAnnotations(kind string) map[string]string {
+	switch kind {
+	case "ScaledObject":
+		return map[string]string{KedaPausedReplicasAnnotation: "0"}
+	case "ScaledJob":
+		return map[string]string{KedaPausedAnnotation: "true"}
+	}
+	return map[string]string{}
+}
+
+func NewAnnotationResourceFromUnstructured(item unstructured.Unstructured) AnnotationResource {
+	currentAnnotations := item.GetAnnotations()
+	annotations := make(map[string]*string)
+	for annotation := range getKedaSleepAnnotations(item.GetKind()) {
+		if value, ok := currentAnnotations[annotation]; ok {
+			annotations[annotation] = &value
+		} else {
+			annotations[annotation] = nil
+		}
+	}
+	return NewAnnotationResource(item.GetAPIVersion(), item.GetKind(), item.GetName(), item.GetNamespace(), annotations)
+}
+
+func (o AnnotationResource) GetName() string {
+	return o.ResourceName
+}
+
+func (o AnnotationResource) GetNamespace() string {
+	return o.ResourceNamespace
+}
+
+// UpdateClient sets the recorded annotations on the resource, removing those
+// recorded as unset.
+func (o AnnotationResource) UpdateClient(ctx context.Context, Client client.Client) error {
+	return SetAnnotations(ctx, Client, o.ResourceApiVersion, o.ResourceKind, o.ResourceNamespace, o.ResourceName, o.ResourceAnnotations)
+}
+
+func (o AnnotationResource) IsAsleep() bool {
+	for annotation, value := range getKedaSleepAnnotations(o.ResourceKind) {
+		if o.ResourceAnnotations[annotation] == nil || *o.ResourceAnnotations[annotation] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (o AnnotationResource) PutToSleep(ctx context.Context, Client client.Client) error {
+	if !o.IsAsleep() {
+		sleepAnnotations := make(map[string]*string)
+		for annotation, value := range getKedaSleepAnnotations(o.ResourceKind) {
+			value := value
+			sleepAnnotations[annotation] = &value
+		}
+		o.ResourceAnnotations = sleepAnnotations
+		return o.UpdateClient(ctx, Client)
+	}
+	return nil
+}
+
+func (o AnnotationResource) Wake(ctx context.Context, Client client.Client) error {
+	err := o.UpdateClient(ctx, Client)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func CastAnnotationToGeneral(resource []AnnotationResource) []ResourceInt {
+	var general []ResourceInt
+	for _, item := range resource {
+		general = append(general, item)
+	}
+	return general
+}
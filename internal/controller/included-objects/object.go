@@ -3,37 +3,95 @@ package object
 import (
 	"context"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Object struct {
-	Client     client.Client
-	IncludeRef string
-	ExcludeRef string
-	Namespace  string
+	Client        client.Client
+	IncludeRef    string
+	ExcludeRef    string
+	Namespace     string
+	LabelSelector labels.Selector
 }
 
-func NewObject(Client client.Client, includeRef, excludeRef, namespace string) Object {
+func NewObject(Client client.Client, includeRef, excludeRef, namespace string, labelSelector labels.Selector) Object {
 	return Object{
 		Client,
 		includeRef,
 		excludeRef,
 		namespace,
+		labelSelector,
 	}
 }
 
+// listOptions restricts the listing of objects to the namespace and, when
+// set, the label selector of the included object.
+func (object Object) listOptions() []client.ListOption {
+	listOptions := []client.ListOption{client.InNamespace(object.Namespace)}
+	if object.LabelSelector != nil && !object.LabelSelector.Empty() {
+		listOptions = append(listOptions, client.MatchingLabelsSelector{Selector: object.LabelSelector})
+	}
+	return listOptions
+}
+
 type ResourceInt interface {
-	PutToSleep(ctx context.Context, Client client.Client) []string
+	PutToSleep(ctx context.Context, Client client.Client) error
 	UpdateClient(ctx context.Context, Client client.Client) error
 	Wake(ctx context.Context, Client client.Client) error
 	GetName() string
 	GetNamespace() string
+	GetKind() string
+	// GetUID returns the UID of the object the state was captured from, if
+	// known.
+	GetUID() types.UID
+	// IsAsleep reports whether the recorded state is the one the resource is
+	// given while asleep.
+	IsAsleep() bool
 }
 
 type Resource struct {
-	ResourceName      string `json:"name"`
-	ResourceKind      string `json:"kind"`
-	ResourceNamespace string `json:"namespace"`
+	ResourceName       string `json:"name"`
+	ResourceKind       string `json:"kind"`
+	ResourceNamespace  string `json:"namespace"`
+	ResourceApiVersion string `json:"apiVersion,omitempty"`
+	// ResourceUID and ResourceVersion identify the object the state was
+	// captured from.
+	ResourceUID     types.UID `json:"uid,omitempty"`
+	ResourceVersion string    `json:"resourceVersion,omitempty"`
+}
+
+func (o Resource) GetKind() string {
+	return o.ResourceKind
+}
+
+func (o Resource) GetUID() types.UID {
+	return o.ResourceUID
+}
+
+// SetIdentity records the UID and resourceVersion of the object a resource
+// was captured from.
+func SetIdentity(resource ResourceInt, item metav1.Object) ResourceInt {
+	switch resource := resource.(type) {
+	case ReplicaResource:
+		resource.ResourceUID, resource.ResourceVersion = item.GetUID(), item.GetResourceVersion()
+		return resource
+	case StatusResource:
+		resource.ResourceUID, resource.ResourceVersion = item.GetUID(), item.GetResourceVersion()
+		return resource
+	case SyncPolicyResource:
+		resource.ResourceUID, resource.ResourceVersion = item.GetUID(), item.GetResourceVersion()
+		return resource
+	case AnnotationResource:
+		resource.ResourceUID, resource.ResourceVersion = item.GetUID(), item.GetResourceVersion()
+		return resource
+	case IngressResource:
+		resource.ResourceUID, resource.ResourceVersion = item.GetUID(), item.GetResourceVersion()
+		return resource
+	}
+	return resource
 }
 
 type ResourceMap interface {
@@ -44,6 +102,8 @@ type ResourceMap interface {
 type ReplicaResource struct {
 	Resource
 	ResourceReplicas int32 `json:"replicas"`
+	// SleepReplicas is the number of replicas kept while asleep.
+	SleepReplicas int32 `json:"-"`
 }
 type ReplicaResourceMap struct {
 	Items map[string][]ReplicaResource
@@ -86,62 +146,44 @@ func (o ReplicaResource) GetNamespace() string {
 	return o.ResourceNamespace
 }
 
+// UpdateClient scales the workload to the recorded replicas.
 func (o ReplicaResource) UpdateClient(ctx context.Context, Client client.Client) error {
-	var resource client.Object
-	var err error
 	switch o.ResourceKind {
-	case "Deployment":
-		resource, err = GetDeployment(ctx, Client, o)
-		if err != nil {
-			return err
-		}
-	case "StatefulSet":
-		resource, err = GetStatefulSet(ctx, Client, o)
-		if err != nil {
-			return err
-		}
-	case "ReplicaSet":
-		resource, err = GetReplicaSet(ctx, Client, o)
-		if err != nil {
-			return err
-		}
-	}
-	err = Client.Update(ctx, resource)
-	if err != nil {
-		return err
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		return scale(ctx, Client, o.Resource, o.ResourceReplicas)
 	}
 	return nil
 }
 
+// Sleep scales the workload down to its sleep replicas and returns its
+// replicas beforehand. Workloads already at or below their sleep replicas are
+// left untouched.
 func (o ReplicaResource) Sleep(ctx context.Context, Client client.Client) (int32, error) {
-	zeroPtr := int32(0)
-	replicasToStore := int32(0)
-	if o.ResourceReplicas != zeroPtr {
-		replicasToStore = o.ResourceReplicas
-		o.ResourceReplicas = zeroPtr
+	replicasToStore := o.ResourceReplicas
+	if o.ResourceReplicas > o.SleepReplicas {
+		o.ResourceReplicas = o.SleepReplicas
 		err := o.UpdateClient(ctx, Client)
 		if err != nil {
-			return zeroPtr, err
+			return replicasToStore, err
 		}
 	}
 	return replicasToStore, nil
 }
 
+func (o ReplicaResource) IsAsleep() bool {
+	return o.ResourceReplicas <= o.SleepReplicas
+}
+
 func (o ReplicaResource) AddToList(replicasMap ReplicaResourceMap) {
 	replicasMap.Items[o.ResourceKind] = append(replicasMap.Items[o.ResourceKind], o)
 }
 
-func (o ReplicaResource) PutToSleep(ctx context.Context, Client client.Client) []string {
-	var failedObjects []string
-	if o.ResourceReplicas != int32(0) {
-		replicas, err := o.Sleep(ctx, Client)
-		if err != nil {
-			failedObjects = append(failedObjects, o.ResourceName)
-		} else {
-			o.ResourceReplicas = replicas
-		}
+func (o ReplicaResource) PutToSleep(ctx context.Context, Client client.Client) error {
+	if o.ResourceReplicas > o.SleepReplicas {
+		_, err := o.Sleep(ctx, Client)
+		return err
 	}
-	return failedObjects
+	return nil
 }
 
 func (o ReplicaResource) Wake(ctx context.Context, Client client.Client) error {
@@ -198,48 +240,49 @@ func (o StatusResource) GetNamespace() string {
 	return o.ResourceNamespace
 }
 
+// UpdateClient sets the spec.suspend field of the resource to the recorded
+// value.
 func (o StatusResource) UpdateClient(ctx context.Context, Client client.Client) error {
-	var resource client.Object
-	var err error
 	switch o.ResourceKind {
-	case "CronJob":
-		resource, err = GetCronjob(ctx, Client, o)
-		if err != nil {
-			return err
-		}
-		err = Client.Update(ctx, resource)
-		if err != nil {
-			return err
-		}
+	case "CronJob", "Kustomization", "HelmRelease":
+		return mergePatch(ctx, Client, o.Resource, map[string]interface{}{
+			"spec": map[string]interface{}{
+				"suspend": IsSuspended(o.ResourceStatus),
+			},
+		})
 	}
 	return nil
 }
 
-func (o StatusResource) Sleep(ctx context.Context, Client client.Client) (*bool, error) {
-	suspendStatus := true
-	statusToStore := false
-	if o.ResourceStatus != &suspendStatus {
-		statusToStore = *o.ResourceStatus
+// IsSuspended returns the value of a suspend field, an unset field meaning
+// that the resource is not suspended.
+func IsSuspended(suspend *bool) bool {
+	return suspend != nil && *suspend
+}
+
+func (o StatusResource) Sleep(ctx context.Context, Client client.Client) (bool, error) {
+	statusToStore := IsSuspended(o.ResourceStatus)
+	if !statusToStore {
+		suspendStatus := true
 		o.ResourceStatus = &suspendStatus
 		err := o.UpdateClient(ctx, Client)
 		if err != nil {
-			return nil, err
+			return statusToStore, err
 		}
 	}
-	return &statusToStore, nil
+	return statusToStore, nil
 }
 
-func (o StatusResource) PutToSleep(ctx context.Context, Client client.Client) []string {
-	var failedObjects []string
-	if !*o.ResourceStatus {
-		status, err := o.Sleep(ctx, Client)
-		if err != nil {
-			failedObjects = append(failedObjects, o.ResourceName)
-		} else {
-			o.ResourceStatus = status
-		}
+func (o StatusResource) PutToSleep(ctx context.Context, Client client.Client) error {
+	if !IsSuspended(o.ResourceStatus) {
+		_, err := o.Sleep(ctx, Client)
+		return err
 	}
-	return failedObjects
+	return nil
+}
+
+func (o StatusResource) IsAsleep() bool {
+	return IsSuspended(o.ResourceStatus)
 }
 
 func (o StatusResource) AddToList(statusMap StatusResourceMap) {
@@ -0,0 +1,73 @@
+package object
+
+import (
+	"context"
+	"encoding/json"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newObject returns an object designating a resource, to be patched. The
+// kinds served by the API server are typed, the others being unstructured.
+func newObject(resource Resource) client.Object {
+	var item client.Object
+	switch resource.ResourceKind {
+	case "Deployment":
+		item = &appsv1.Deployment{}
+	case "StatefulSet":
+		item = &appsv1.StatefulSet{}
+	case "ReplicaSet":
+		item = &appsv1.ReplicaSet{}
+	case "CronJob":
+		item = &batchv1.CronJob{}
+	case "Ingress":
+		item = &networkingv1.Ingress{}
+	default:
+		unstructuredItem := &unstructured.Unstructured{}
+		unstructuredItem.SetAPIVersion(resource.ResourceApiVersion)
+		unstructuredItem.SetKind(resource.ResourceKind)
+		item = unstructuredItem
+	}
+	item.SetName(resource.ResourceName)
+	item.SetNamespace(resource.ResourceNamespace)
+	return item
+}
+
+func newMergePatch(patch map[string]interface{}) (client.Patch, error) {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return client.RawPatch(types.MergePatchType, data), nil
+}
+
+// mergePatch applies a JSON merge patch to a resource, so that the fields it
+// leaves out are not overwritten with a stale copy of those set by other
+// controllers. A nil value removes a field.
+func mergePatch(ctx context.Context, Client client.Client, resource Resource, patch map[string]interface{}) error {
+	mergePatch, err := newMergePatch(patch)
+	if err != nil {
+		return err
+	}
+	return Client.Patch(ctx, newObject(resource), mergePatch)
+}
+
+// scale sets the replicas of a workload through its scale subresource,
+// leaving the rest of its spec untouched.
+func scale(ctx context.Context, Client client.Client, resource Resource, replicas int32) error {
+	mergePatch, err := newMergePatch(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return Client.SubResource("scale").Patch(ctx, newObject(resource), mergePatch, client.WithSubResourceBody(&autoscalingv1.Scale{}))
+}
@@ -5,8 +5,6 @@ import (
 	"regexp"
 
 	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func getReplicaSetsByPattern(object Object, allObjects *appsv1.ReplicaSetList) *appsv1.ReplicaSetList {
@@ -30,7 +28,7 @@ func getReplicaSetsByPattern(object Object, allObjects *appsv1.ReplicaSetList) *
 
 func getAllReplicaSets(ctx context.Context, object Object) (*appsv1.ReplicaSetList, error) {
 	replicasetList := &appsv1.ReplicaSetList{}
-	err := object.Client.List(ctx, replicasetList, client.InNamespace(object.Namespace))
+	err := object.Client.List(ctx, replicasetList, object.listOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -45,8 +43,7 @@ func GetReplicaSetListNames(objects *appsv1.ReplicaSetList) []string {
 	return listNames
 }
 
-func FetchReplicaSets(ctx context.Context, Client client.Client, includeRef, excludeRef, namespace string) (*appsv1.ReplicaSetList, error) {
-	resource := NewObject(Client, includeRef, excludeRef, namespace)
+func FetchReplicaSets(ctx context.Context, resource Object) (*appsv1.ReplicaSetList, error) {
 	allObjects, err := getAllReplicaSets(ctx, resource)
 	if err != nil {
 		return nil, err
@@ -54,13 +51,3 @@ func FetchReplicaSets(ctx context.Context, Client client.Client, includeRef, exc
 	filteredObjects := getReplicaSetsByPattern(resource, allObjects)
 	return filteredObjects, nil
 }
-
-func GetReplicaSet(ctx context.Context, Client client.Client, resource ReplicaResource) (*appsv1.ReplicaSet, error) {
-	replicaset := appsv1.ReplicaSet{}
-	err := Client.Get(ctx, types.NamespacedName{Name: resource.ResourceName, Namespace: resource.ResourceNamespace}, &replicaset)
-	if err != nil {
-		return nil, err
-	}
-	replicaset.Spec.Replicas = &resource.ResourceReplicas
-	return &replicaset, nil
-}
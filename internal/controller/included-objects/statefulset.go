@@ -5,8 +5,6 @@ import (
 	"regexp"
 
 	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func getStatefulsetsByPattern(object Object, allObjects *appsv1.StatefulSetList) *appsv1.StatefulSetList {
@@ -28,9 +26,9 @@ func getStatefulsetsByPattern(object Object, allObjects *appsv1.StatefulSetList)
 	return filteredDeployments
 }
 
-func getAllStatefulsets(ctx context.Context, Client client.Client, namespace string) (*appsv1.StatefulSetList, error) {
+func getAllStatefulsets(ctx context.Context, object Object) (*appsv1.StatefulSetList, error) {
 	statefulsetList := &appsv1.StatefulSetList{}
-	err := Client.List(ctx, statefulsetList, client.InNamespace(namespace))
+	err := object.Client.List(ctx, statefulsetList, object.listOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -45,22 +43,11 @@ func GetStatefulsetListNames(objects *appsv1.StatefulSetList) []string {
 	return listNames
 }
 
-func FetchStatefulsets(ctx context.Context, Client client.Client, includeRef, excludeRef, namespace string) (*appsv1.StatefulSetList, error) {
-	resource := NewObject(Client, includeRef, excludeRef, namespace)
-	allObjects, err := getAllStatefulsets(ctx, Client, namespace)
+func FetchStatefulsets(ctx context.Context, resource Object) (*appsv1.StatefulSetList, error) {
+	allObjects, err := getAllStatefulsets(ctx, resource)
 	if err != nil {
 		return nil, err
 	}
 	filteredObjects := getStatefulsetsByPattern(resource, allObjects)
 	return filteredObjects, nil
 }
-
-func GetStatefulSet(ctx context.Context, Client client.Client, resource ReplicaResource) (*appsv1.StatefulSet, error) {
-	statefulset := appsv1.StatefulSet{}
-	err := Client.Get(ctx, types.NamespacedName{Name: resource.ResourceName, Namespace: resource.ResourceNamespace}, &statefulset)
-	if err != nil {
-		return nil, err
-	}
-	statefulset.Spec.Replicas = &resource.ResourceReplicas
-	return &statefulset, nil
-}
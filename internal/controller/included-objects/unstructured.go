@@ -0,0 +1,57 @@
+package object
+
+import (
+	"context"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getUnstructuredByPattern(object Object, allObjects *unstructured.UnstructuredList) *unstructured.UnstructuredList {
+	filteredObjects := &unstructured.UnstructuredList{}
+	if object.IncludeRef == "" && object.ExcludeRef == "" {
+		return allObjects
+	}
+	if object.IncludeRef == object.ExcludeRef {
+		return nil
+	}
+
+	includeRe := regexp.MustCompile(object.IncludeRef)
+	excludeRe := regexp.MustCompile(object.ExcludeRef)
+	for _, item := range allObjects.Items {
+		if includeRe.MatchString(item.GetName()) && !excludeRe.MatchString(item.GetName()) {
+			filteredObjects.Items = append(filteredObjects.Items, item)
+		}
+	}
+	return filteredObjects
+}
+
+func getAllUnstructured(ctx context.Context, object Object, apiVersion, kind string) (*unstructured.UnstructuredList, error) {
+	objectList := &unstructured.UnstructuredList{}
+	objectList.SetAPIVersion(apiVersion)
+	objectList.SetKind(kind + "List")
+	err := object.Client.List(ctx, objectList, object.listOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return objectList, err
+}
+
+func GetUnstructuredListNames(objects *unstructured.UnstructuredList) []string {
+	var listNames []string
+	for _, objectItem := range objects.Items {
+		listNames = append(listNames, objectItem.GetName())
+	}
+	return listNames
+}
+
+// FetchUnstructured lists objects of kinds that are not part of the client
+// scheme, such as the custom resources of GitOps controllers.
+func FetchUnstructured(ctx context.Context, resource Object, apiVersion, kind string) (*unstructured.UnstructuredList, error) {
+	allObjects, err := getAllUnstructured(ctx, resource, apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+	filteredObjects := getUnstructuredByPattern(resource, allObjects)
+	return filteredObjects, nil
+}
@@ -0,0 +1,104 @@
+package kronosapp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestIngressBackend(name string, port int32) networkingv1.IngressBackend {
+	return networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: name,
+			Port: networkingv1.ServiceBackendPort{Number: port},
+		},
+	}
+}
+
+func TestIngressBackendSwap(t *testing.T) {
+	pathType := networkingv1.PathTypePrefix
+	web := newTestIngressBackend("web", 80)
+	api := newTestIngressBackend("api", 8080)
+	sleeping := newTestIngressBackend("kronos-sleeping", 80)
+	rules := []networkingv1.IngressRule{
+		{
+			Host: "app.example.com",
+			IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+				{Path: "/", PathType: &pathType, Backend: web},
+				{Path: "/api", PathType: &pathType, Backend: api},
+			}}},
+		},
+		{Host: "empty.example.com"},
+	}
+	tests := []struct {
+		name           string
+		defaultBackend *networkingv1.IngressBackend
+	}{
+		{name: "rules only"},
+		{name: "default backend", defaultBackend: &web},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec:       networkingv1.IngressSpec{DefaultBackend: test.defaultBackend, Rules: rules},
+			}
+			original := ingress.Spec.DeepCopy()
+			Client := newTestClient(t, ingress.DeepCopy())
+			resource := object.NewIngressResource(*ingress, &sleeping, "2024-01-01T08:00:00Z")
+			if resource.IsAsleep() {
+				t.Fatal("expected the Ingress not to be reported asleep")
+			}
+
+			err := resource.PutToSleep(ctx, Client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Client.Get(ctx, client.ObjectKeyFromObject(ingress), ingress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.defaultBackend != nil && !reflect.DeepEqual(ingress.Spec.DefaultBackend, &sleeping) {
+				t.Errorf("expected the default backend to be the sleeping Service, got %+v", ingress.Spec.DefaultBackend)
+			}
+			if test.defaultBackend == nil && ingress.Spec.DefaultBackend != nil {
+				t.Errorf("expected no default backend to be added, got %+v", ingress.Spec.DefaultBackend)
+			}
+			for _, path := range ingress.Spec.Rules[0].HTTP.Paths {
+				if !reflect.DeepEqual(path.Backend, sleeping) {
+					t.Errorf("expected %s to point to the sleeping Service, got %+v", path.Path, path.Backend)
+				}
+			}
+			if ingress.Spec.Rules[1].HTTP != nil || ingress.Spec.Rules[1].Host != "empty.example.com" {
+				t.Errorf("expected the rule without paths to be kept, got %+v", ingress.Spec.Rules[1])
+			}
+			if ingress.Annotations[object.WakeTimeAnnotation] != "2024-01-01T08:00:00Z" {
+				t.Errorf("expected the wake time to be set, got %v", ingress.Annotations)
+			}
+			if !object.NewIngressResource(*ingress, &sleeping, "").IsAsleep() {
+				t.Error("expected the redirected Ingress to be reported asleep")
+			}
+
+			err = resource.Wake(ctx, Client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Client.Get(ctx, client.ObjectKeyFromObject(ingress), ingress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(&ingress.Spec, original) {
+				t.Errorf("expected the original backends to be restored, got %+v", ingress.Spec)
+			}
+			if _, ok := ingress.Annotations[object.WakeTimeAnnotation]; ok {
+				t.Errorf("expected the wake time to be removed, got %v", ingress.Annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package kronosapp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+// maxResourceStatuses caps the number of objects listed in the status of a
+// KronosApp, keeping it well below the size limit of an object. The objects
+// met with an error are listed regardless, as their backoff is kept there.
+const maxResourceStatuses = 100
+
+// baseRetryDelay and maxRetryDelay bound the exponential backoff applied to
+// the objects that could not be put to sleep or woken up, and minRequeueTime
+// the delay before the KronosApp is reconciled again to retry them.
+const (
+	baseRetryDelay = 10 * time.Second
+	maxRetryDelay  = 10 * time.Minute
+	minRequeueTime = time.Second
+)
+
+// getRetryDelay returns the delay before retrying an action that failed the
+// given number of consecutive times.
+func getRetryDelay(failures int32) time.Duration {
+	delay := baseRetryDelay
+	for i := int32(1); i < failures; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// transition records what happens to each included object while the objects
+// are put to sleep or woken up.
+type transition struct {
+	// failed and drifted hold the names of the objects that could not be
+	// handled and of those found changed while asleep, by kind.
+	failed    map[string][]string
+	drifted   map[string][]string
+	resources []v1alpha1.ManagedResource
+	index     map[string]int
+	// uids holds the UIDs of the objects acted on, for their events.
+	uids map[string]types.UID
+	// previous holds the objects reported by the previous reconciliation.
+	previous map[string]v1alpha1.ManagedResource
+	now      time.Time
+}
+
+func newTransition(previous []v1alpha1.ManagedResource, now time.Time) *transition {
+	t := &transition{
+		failed:   make(map[string][]string),
+		drifted:  make(map[string][]string),
+		index:    make(map[string]int),
+		uids:     make(map[string]types.UID),
+		previous: make(map[string]v1alpha1.ManagedResource),
+		now:      now,
+	}
+	for _, managedResource := range previous {
+		t.previous[getManagedResourceKey(managedResource)] = managedResource
+	}
+	return t
+}
+
+func getResourceKey(resource object.ResourceInt) string {
+	return fmt.Sprintf("%s/%s/%s", resource.GetKind(), resource.GetNamespace(), resource.GetName())
+}
+
+func getManagedResourceKey(managedResource v1alpha1.ManagedResource) string {
+	return fmt.Sprintf("%s/%s/%s", managedResource.Kind, managedResource.Namespace, managedResource.Name)
+}
+
+// newManagedResource reports a resource along with the state recorded for it.
+func newManagedResource(resource object.ResourceInt) v1alpha1.ManagedResource {
+	managedResource := v1alpha1.ManagedResource{
+		Kind:      resource.GetKind(),
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+	}
+	switch resource := resource.(type) {
+	case object.ReplicaResource:
+		managedResource.OriginalReplicas = ptr.To(resource.ResourceReplicas)
+	case object.StatusResource:
+		managedResource.OriginalSuspend = ptr.To(object.IsSuspended(resource.ResourceStatus))
+	}
+	return managedResource
+}
+
+func (t *transition) put(managedResource v1alpha1.ManagedResource) {
+	key := getManagedResourceKey(managedResource)
+	if managedResource.LastError != "" {
+		t.failed[managedResource.Kind] = append(t.failed[managedResource.Kind], managedResource.Name)
+	}
+	if index, ok := t.index[key]; ok {
+		t.resources[index] = managedResource
+		return
+	}
+	t.index[key] = len(t.resources)
+	t.resources = append(t.resources, managedResource)
+}
+
+// record records the outcome of an action taken on a resource, holding the
+// state restored on wake. A failed action is retried after a delay growing
+// with its consecutive failures.
+func (t *transition) record(resource object.ResourceInt, state, action string, err error) {
+	managedResource := newManagedResource(resource)
+	managedResource.State = state
+	managedResource.LastAction = action
+	t.uids[getResourceKey(resource)] = resource.GetUID()
+	if err != nil {
+		managedResource.LastError = err.Error()
+		managedResource.Failures = 1
+		if previous, ok := t.previous[getResourceKey(resource)]; ok && previous.LastError != "" {
+			managedResource.Failures = previous.Failures + 1
+		}
+		managedResource.NextRetryTime = &metav1.Time{Time: t.now.Add(getRetryDelay(managedResource.Failures))}
+	}
+	t.put(managedResource)
+}
+
+// failedPreviously reports whether the last action taken on a resource
+// failed.
+func (t *transition) failedPreviously(resource object.ResourceInt) bool {
+	previous, ok := t.previous[getResourceKey(resource)]
+	return ok && previous.LastError != ""
+}
+
+// isBackingOff reports whether a resource whose last action failed must wait
+// before the action is retried, in which case its failure is reported again.
+func (t *transition) isBackingOff(resource object.ResourceInt) bool {
+	previous, ok := t.previous[getResourceKey(resource)]
+	if !ok || previous.LastError == "" || previous.NextRetryTime == nil || !t.now.Before(previous.NextRetryTime.Time) {
+		return false
+	}
+	t.put(previous)
+	return true
+}
+
+// recordDrift records a resource found changed while asleep.
+func (t *transition) recordDrift(resource object.ResourceInt) {
+	t.drifted[resource.GetKind()] = append(t.drifted[resource.GetKind()], resource.GetNamespace()+"/"+resource.GetName())
+}
+
+// getRetryTime returns the earliest time at which a failed action is due to
+// be retried, the zero time when there is none.
+func (t *transition) getRetryTime() time.Time {
+	var retryTime time.Time
+	for _, managedResource := range t.resources {
+		if managedResource.NextRetryTime == nil {
+			continue
+		}
+		if retryTime.IsZero() || managedResource.NextRetryTime.Time.Before(retryTime) {
+			retryTime = managedResource.NextRetryTime.Time
+		}
+	}
+	return retryTime
+}
+
+// getRetryRequeueTime returns the delay before the KronosApp is reconciled
+// again to retry the failed actions.
+func (t *transition) getRetryRequeueTime() time.Duration {
+	retryTime := time.Until(t.getRetryTime())
+	if retryTime < minRequeueTime {
+		return minRequeueTime
+	}
+	return retryTime
+}
+
+// err returns an error listing every failed action, nil when there is none.
+func (t *transition) err() error {
+	var failures []string
+	for _, managedResource := range t.resources {
+		if managedResource.LastError != "" {
+			failures = append(failures, fmt.Sprintf("%s %s/%s: %s", managedResource.Kind, managedResource.Namespace, managedResource.Name, managedResource.LastError))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d objects could not be handled: %s", len(failures), strings.Join(failures, "; "))
+}
+
+// getResourceStatuses lists the included objects along with the objects
+// handled during the transition, the objects left untouched keeping the last
+// action reported previously. It returns those met with an error first,
+// all of them since the next reconciliation reads their failures back, then
+// the others up to maxResourceStatuses in all, along with the number left
+// out.
+func (t *transition) getResourceStatuses(includedObjects *ObjectList) ([]v1alpha1.ManagedResource, int32) {
+	for _, kind := range getAllKinds() {
+		if !includedObjects.ContainsKind(kind) {
+			continue
+		}
+		for _, resource := range includedObjects.GetResources(kind) {
+			if _, ok := t.index[getResourceKey(resource)]; !ok {
+				t.record(resource, v1alpha1.ResourceStateAwake, "", nil)
+			}
+		}
+	}
+	resources := make([]v1alpha1.ManagedResource, len(t.resources))
+	copy(resources, t.resources)
+	for index := range resources {
+		if resources[index].LastAction == "" {
+			resources[index].LastAction = t.previous[getManagedResourceKey(resources[index])].LastAction
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if (resources[i].LastError != "") != (resources[j].LastError != "") {
+			return resources[i].LastError != ""
+		}
+		if resources[i].Kind != resources[j].Kind {
+			return resources[i].Kind < resources[j].Kind
+		}
+		if resources[i].Namespace != resources[j].Namespace {
+			return resources[i].Namespace < resources[j].Namespace
+		}
+		return resources[i].Name < resources[j].Name
+	})
+	limit := maxResourceStatuses
+	for limit < len(resources) && resources[limit].LastError != "" {
+		limit++
+	}
+	if len(resources) > limit {
+		return resources[:limit], int32(len(resources) - limit)
+	}
+	return resources, 0
+}
@@ -0,0 +1,114 @@
+package kronosapp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+)
+
+func newTestReplicaResource(name string) object.ReplicaResource {
+	return object.ReplicaResource{
+		Resource: object.Resource{
+			ResourceName:      name,
+			ResourceKind:      "Deployment",
+			ResourceNamespace: "default",
+		},
+		ResourceReplicas: 2,
+	}
+}
+
+func TestGetRetryDelay(t *testing.T) {
+	tests := []struct {
+		failures int32
+		expected time.Duration
+	}{
+		{failures: 0, expected: baseRetryDelay},
+		{failures: 1, expected: baseRetryDelay},
+		{failures: 2, expected: 2 * baseRetryDelay},
+		{failures: 4, expected: 8 * baseRetryDelay},
+		{failures: 7, expected: maxRetryDelay},
+		{failures: 1000, expected: maxRetryDelay},
+	}
+	for _, test := range tests {
+		if delay := getRetryDelay(test.failures); delay != test.expected {
+			t.Errorf("expected %v after %d failures, got %v", test.expected, test.failures, delay)
+		}
+	}
+}
+
+func TestTransitionBackoff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resource := newTestReplicaResource("web")
+
+	first := newTransition(nil, now)
+	if first.failedPreviously(resource) || first.isBackingOff(resource) {
+		t.Fatal("expected a resource never handled not to be backing off")
+	}
+	first.record(resource, v1alpha1.ResourceStateAwake, "Sleep", errors.New("denied"))
+	failed := first.resources[0]
+	if failed.Failures != 1 || !failed.NextRetryTime.Time.Equal(now.Add(baseRetryDelay)) {
+		t.Fatalf("expected a first failure retried after %v, got %+v", baseRetryDelay, failed)
+	}
+
+	backingOff := newTransition(first.resources, now.Add(baseRetryDelay/2))
+	if !backingOff.failedPreviously(resource) {
+		t.Error("expected the previous failure to be reported")
+	}
+	if !backingOff.isBackingOff(resource) {
+		t.Fatal("expected the resource to back off before its retry time")
+	}
+	if len(backingOff.resources) != 1 || backingOff.resources[0].Failures != 1 || len(backingOff.failed["Deployment"]) != 1 {
+		t.Errorf("expected the failure to be reported again, got %+v", backingOff.resources)
+	}
+
+	retried := newTransition(first.resources, now.Add(baseRetryDelay))
+	if retried.isBackingOff(resource) {
+		t.Fatal("expected the resource to be retried at its retry time")
+	}
+	retried.record(resource, v1alpha1.ResourceStateAwake, "Sleep", errors.New("denied"))
+	if retried.resources[0].Failures != 2 || !retried.resources[0].NextRetryTime.Time.Equal(now.Add(3*baseRetryDelay)) {
+		t.Errorf("expected a second failure retried after %v, got %+v", 2*baseRetryDelay, retried.resources[0])
+	}
+
+	recovered := newTransition(retried.resources, now.Add(3*baseRetryDelay))
+	recovered.record(resource, v1alpha1.ResourceStateAsleep, "Sleep", nil)
+	if recovered.resources[0].Failures != 0 || recovered.resources[0].NextRetryTime != nil || recovered.err() != nil {
+		t.Errorf("expected a success to reset the backoff, got %+v", recovered.resources[0])
+	}
+}
+
+func TestGetResourceStatusesKeepsFailures(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := newTransition(nil, now)
+	total := maxResourceStatuses + 20
+	for i := 0; i < total; i++ {
+		var err error
+		if i%2 == 0 {
+			err = errors.New("denied")
+		}
+		t1.record(newTestReplicaResource(fmt.Sprintf("web-%03d", i)), v1alpha1.ResourceStateAwake, "Sleep", err)
+	}
+	resources, omitted := t1.getResourceStatuses(&ObjectList{})
+	if len(resources) != maxResourceStatuses || omitted != 20 {
+		t.Fatalf("expected %d objects listed and 20 omitted, got %d and %d", maxResourceStatuses, len(resources), omitted)
+	}
+
+	t2 := newTransition(nil, now)
+	for i := 0; i < total; i++ {
+		t2.record(newTestReplicaResource(fmt.Sprintf("web-%03d", i)), v1alpha1.ResourceStateAwake, "Sleep", errors.New("denied"))
+	}
+	resources, omitted = t2.getResourceStatuses(&ObjectList{})
+	if len(resources) != total || omitted != 0 {
+		t.Fatalf("expected every failed object to be listed, got %d listed and %d omitted", len(resources), omitted)
+	}
+	next := newTransition(resources, now)
+	for i := 0; i < total; i++ {
+		if !next.failedPreviously(newTestReplicaResource(fmt.Sprintf("web-%03d", i))) {
+			t.Fatalf("expected the failure of web-%03d to be read back", i)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package kronosapp
+
+import (
+	"context"
+	"testing"
+
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestKedaObject(kind string, annotations map[string]string) *unstructured.Unstructured {
+	item := &unstructured.Unstructured{}
+	item.SetAPIVersion("keda.sh/v1alpha1")
+	item.SetKind(kind)
+	item.SetName("web")
+	item.SetNamespace("default")
+	item.SetAnnotations(annotations)
+	return item
+}
+
+func TestKedaPauseAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        string
+		annotations map[string]string
+		asleep      map[string]string
+	}{
+		{
+			name:   "ScaledObject not paused",
+			kind:   "ScaledObject",
+			asleep: map[string]string{object.KedaPausedReplicasAnnotation: "0"},
+		},
+		{
+			name:        "ScaledObject paused at other replicas",
+			kind:        "ScaledObject",
+			annotations: map[string]string{object.KedaPausedReplicasAnnotation: "2", "team": "payments"},
+			asleep:      map[string]string{object.KedaPausedReplicasAnnotation: "0", "team": "payments"},
+		},
+		{
+			name:   "ScaledJob not paused",
+			kind:   "ScaledJob",
+			asleep: map[string]string{object.KedaPausedAnnotation: "true"},
+		},
+		{
+			name:        "ScaledJob explicitly not paused",
+			kind:        "ScaledJob",
+			annotations: map[string]string{object.KedaPausedAnnotation: "false"},
+			asleep:      map[string]string{object.KedaPausedAnnotation: "true"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			item := newTestKedaObject(test.kind, test.annotations)
+			Client := newTestClient(t, item.DeepCopy())
+			resource := object.NewAnnotationResourceFromUnstructured(*item)
+			if resource.IsAsleep() {
+				t.Fatal("expected the object not to be reported asleep")
+			}
+
+			err := resource.PutToSleep(ctx, Client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Client.Get(ctx, client.ObjectKeyFromObject(item), item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertAnnotations(t, "asleep", item.GetAnnotations(), test.asleep)
+			if !object.NewAnnotationResourceFromUnstructured(*item).IsAsleep() {
+				t.Error("expected the paused object to be reported asleep")
+			}
+
+			err = resource.Wake(ctx, Client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Client.Get(ctx, client.ObjectKeyFromObject(item), item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertAnnotations(t, "awake", item.GetAnnotations(), test.annotations)
+		})
+	}
+}
+
+func assertAnnotations(t *testing.T, state string, annotations, expected map[string]string) {
+	t.Helper()
+	if len(annotations) != len(expected) {
+		t.Errorf("expected the annotations %v %s, got %v", expected, state, annotations)
+		return
+	}
+	for annotation, value := range expected {
+		if annotations[annotation] != value {
+			t.Errorf("expected the annotations %v %s, got %v", expected, state, annotations)
+			return
+		}
+	}
+}
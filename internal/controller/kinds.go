@@ -0,0 +1,221 @@
+package kronosapp
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// supportedKind describes a kind of objects put to sleep and the list of the
+// ObjectList holding them.
+type supportedKind struct {
+	apiVersion string
+	kind       string
+	// newObject returns an empty object of the kind, to watch it.
+	newObject func() client.Object
+	// unstructuredList returns the list of the kinds of third-party
+	// controllers (GitOps, KEDA), whose CRDs may not be installed and whose
+	// objects are kept unstructured. It is nil for the built-in kinds.
+	unstructuredList func(objectList *ObjectList) **unstructured.UnstructuredList
+	lists            kindLists
+}
+
+// supportedKinds lists the supported kinds in the order they are put to
+// sleep. GitOps objects come first so that their controllers are suspended
+// before the workloads they reconcile get scaled down, followed by the
+// Ingresses routed to those workloads and the KEDA objects autoscaling them.
+var supportedKinds = []supportedKind{
+	newCustomResourceKind("argoproj.io/v1alpha1", "Application", func(objectList *ObjectList) **unstructured.UnstructuredList {
+		return &objectList.Applications
+	}),
+	newCustomResourceKind("kustomize.toolkit.fluxcd.io/v1", "Kustomization", func(objectList *ObjectList) **unstructured.UnstructuredList {
+		return &objectList.Kustomizations
+	}),
+	newCustomResourceKind("helm.toolkit.fluxcd.io/v2", "HelmRelease", func(objectList *ObjectList) **unstructured.UnstructuredList {
+		return &objectList.HelmReleases
+	}),
+	newBuiltinKind("networking.k8s.io/v1", "Ingress", func(objectList *ObjectList) **networkingv1.IngressList {
+		return &objectList.Ingresses
+	}, func(list *networkingv1.IngressList) *[]networkingv1.Ingress {
+		return &list.Items
+	}),
+	newCustomResourceKind("keda.sh/v1alpha1", "ScaledObject", func(objectList *ObjectList) **unstructured.UnstructuredList {
+		return &objectList.ScaledObjects
+	}),
+	newCustomResourceKind("keda.sh/v1alpha1", "ScaledJob", func(objectList *ObjectList) **unstructured.UnstructuredList {
+		return &objectList.ScaledJobs
+	}),
+	newBuiltinKind("apps/v1", "Deployment", func(objectList *ObjectList) **appsv1.DeploymentList {
+		return &objectList.Deployments
+	}, func(list *appsv1.DeploymentList) *[]appsv1.Deployment {
+		return &list.Items
+	}),
+	newBuiltinKind("apps/v1", "StatefulSet", func(objectList *ObjectList) **appsv1.StatefulSetList {
+		return &objectList.StatefulSets
+	}, func(list *appsv1.StatefulSetList) *[]appsv1.StatefulSet {
+		return &list.Items
+	}),
+	newBuiltinKind("apps/v1", "ReplicaSet", func(objectList *ObjectList) **appsv1.ReplicaSetList {
+		return &objectList.ReplicaSets
+	}, func(list *appsv1.ReplicaSetList) *[]appsv1.ReplicaSet {
+		return &list.Items
+	}),
+	newBuiltinKind("batch/v1", "CronJob", func(objectList *ObjectList) **batchv1.CronJobList {
+		return &objectList.CronJobs
+	}, func(list *batchv1.CronJobList) *[]batchv1.CronJob {
+		return &list.Items
+	}),
+}
+
+func newBuiltinKind[L, T any, PT interface {
+	*T
+	client.Object
+}](apiVersion, kind string, list func(objectList *ObjectList) **L, items func(list *L) *[]T) supportedKind {
+	return supportedKind{
+		apiVersion: apiVersion,
+		kind:       kind,
+		newObject: func() client.Object {
+			return PT(new(T))
+		},
+		lists: objectLists[L, T, PT]{list: list, items: items},
+	}
+}
+
+func newCustomResourceKind(apiVersion, kind string, list func(objectList *ObjectList) **unstructured.UnstructuredList) supportedKind {
+	return supportedKind{
+		apiVersion: apiVersion,
+		kind:       kind,
+		newObject: func() client.Object {
+			item := &unstructured.Unstructured{}
+			item.SetAPIVersion(apiVersion)
+			item.SetKind(kind)
+			return item
+		},
+		unstructuredList: list,
+		lists: objectLists[unstructured.UnstructuredList, unstructured.Unstructured, *unstructured.Unstructured]{
+			list: list,
+			items: func(list *unstructured.UnstructuredList) *[]unstructured.Unstructured {
+				return &list.Items
+			},
+		},
+	}
+}
+
+func (k supportedKind) isCustomResource() bool {
+	return k.unstructuredList != nil
+}
+
+func (k supportedKind) getGroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(k.apiVersion, k.kind)
+}
+
+// getSupportedKind returns the description of a supported kind.
+func getSupportedKind(kind string) (supportedKind, bool) {
+	for _, k := range supportedKinds {
+		if k.kind == kind {
+			return k, true
+		}
+	}
+	return supportedKind{}, false
+}
+
+// kindLists handles the list of a kind held by an ObjectList, which is nil
+// when it holds no object of the kind.
+type kindLists interface {
+	contains(objectList *ObjectList) bool
+	count(objectList *ObjectList) int
+	// filter removes the objects skipReason returns a reason for, recording
+	// them as skipped, and drops the list when left empty.
+	filter(objectList *ObjectList, kind string, skipReason func(kind string, item metav1.Object) string)
+	// merge appends the objects of another list that are not already part
+	// of the list.
+	merge(objectList, other *ObjectList)
+}
+
+type objectLists[L, T any, PT interface {
+	*T
+	client.Object
+}] struct {
+	list  func(objectList *ObjectList) **L
+	items func(list *L) *[]T
+}
+
+func (l objectLists[L, T, PT]) contains(objectList *ObjectList) bool {
+	return *l.list(objectList) != nil
+}
+
+func (l objectLists[L, T, PT]) count(objectList *ObjectList) int {
+	list := *l.list(objectList)
+	if list == nil {
+		return 0
+	}
+	return len(*l.items(list))
+}
+
+func (l objectLists[L, T, PT]) filter(objectList *ObjectList, kind string, skipReason func(kind string, item metav1.Object) string) {
+	list := l.list(objectList)
+	if *list == nil {
+		return
+	}
+	items := l.items(*list)
+	*items = filterItems[T, PT](*items, kind, skipReason, &objectList.Skipped)
+	if len(*items) == 0 {
+		*list = nil
+	}
+}
+
+func (l objectLists[L, T, PT]) merge(objectList, other *ObjectList) {
+	otherList := *l.list(other)
+	if otherList == nil {
+		return
+	}
+	list := l.list(objectList)
+	if *list == nil {
+		*list = new(L)
+	}
+	items := l.items(*list)
+	*items = appendNewItems[T, PT](*items, *l.items(otherList))
+}
+
+func getSupportedObjectsApiVersionAndKind() *APIVersionKindMap {
+	kindToAPIVersion := NewEmptyAPIVersionKindMap()
+	for _, k := range supportedKinds {
+		kindToAPIVersion.Add(k.apiVersion, k.kind)
+	}
+	return kindToAPIVersion
+}
+
+// getAllKinds returns the supported kinds in the order they are put to sleep.
+func getAllKinds() []string {
+	allKinds := make([]string, 0, len(supportedKinds))
+	for _, k := range supportedKinds {
+		allKinds = append(allKinds, k.kind)
+	}
+	return allKinds
+}
+
+// getWakeOrder returns the supported kinds in the order they are woken up,
+// restoring GitOps objects only once their workloads are back.
+func getWakeOrder() []string {
+	allKinds := getAllKinds()
+	wakeOrder := make([]string, 0, len(allKinds))
+	for index := len(allKinds) - 1; index >= 0; index-- {
+		wakeOrder = append(wakeOrder, allKinds[index])
+	}
+	return wakeOrder
+}
+
+// isCustomResourceApiVersion reports whether the apiVersion belongs to a
+// third-party controller (GitOps, KEDA) whose CRDs may not be installed.
+func isCustomResourceApiVersion(apiVersion string) bool {
+	for _, k := range supportedKinds {
+		if k.apiVersion == apiVersion && k.isCustomResource() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package kronosapp
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// newTestObjectList returns a list holding an object named name of every
+// supported kind.
+func newTestObjectList(name string) *ObjectList {
+	objectMeta := metav1.ObjectMeta{Name: name, Namespace: "default"}
+	newUnstructuredList := func(apiVersion, kind string) *unstructured.UnstructuredList {
+		item := unstructured.Unstructured{}
+		item.SetAPIVersion(apiVersion)
+		item.SetKind(kind)
+		item.SetName(name)
+		item.SetNamespace("default")
+		return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{item}}
+	}
+	return &ObjectList{
+		Deployments:    &appsv1.DeploymentList{Items: []appsv1.Deployment{{ObjectMeta: objectMeta}}},
+		StatefulSets:   &appsv1.StatefulSetList{Items: []appsv1.StatefulSet{{ObjectMeta: objectMeta}}},
+		ReplicaSets:    &appsv1.ReplicaSetList{Items: []appsv1.ReplicaSet{{ObjectMeta: objectMeta}}},
+		CronJobs:       &batchv1.CronJobList{Items: []batchv1.CronJob{{ObjectMeta: objectMeta}}},
+		Ingresses:      &networkingv1.IngressList{Items: []networkingv1.Ingress{{ObjectMeta: objectMeta}}},
+		Applications:   newUnstructuredList("argoproj.io/v1alpha1", "Application"),
+		Kustomizations: newUnstructuredList("kustomize.toolkit.fluxcd.io/v1", "Kustomization"),
+		HelmReleases:   newUnstructuredList("helm.toolkit.fluxcd.io/v2", "HelmRelease"),
+		ScaledObjects:  newUnstructuredList("keda.sh/v1alpha1", "ScaledObject"),
+		ScaledJobs:     newUnstructuredList("keda.sh/v1alpha1", "ScaledJob"),
+	}
+}
+
+func TestSupportedKindsLists(t *testing.T) {
+	objectList := ObjectList{}
+	objectList.merge(newTestObjectList("web"))
+	objectList.merge(newTestObjectList("web"))
+	objectList.merge(newTestObjectList("api"))
+	for _, k := range supportedKinds {
+		if count := k.lists.count(&objectList); count != 2 {
+			t.Errorf("expected 2 objects of kind %s once merged, got %d", k.kind, count)
+		}
+	}
+
+	objectList.filterObjects(func(kind string, item metav1.Object) string {
+		if item.GetName() == "api" {
+			return "skipped"
+		}
+		return ""
+	})
+	if len(objectList.Skipped) != len(supportedKinds) {
+		t.Errorf("expected an object of each kind to be skipped, got %+v", objectList.Skipped)
+	}
+	objectList.filterObjects(func(kind string, item metav1.Object) string {
+		return "skipped"
+	})
+	for _, k := range supportedKinds {
+		if objectList.ContainsKind(k.kind) {
+			t.Errorf("expected the list of kind %s to be dropped once empty", k.kind)
+		}
+	}
+}
+
+func TestGetManagedObjectTypes(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "keda.sh", Version: "v1alpha1", Kind: "ScaledObject"}, meta.RESTScopeNamespace)
+	var kinds []string
+	for _, item := range getManagedObjectTypes(mapper) {
+		gvk, err := apiutil.GVKForObject(item, newTestClient(t).Scheme())
+		if err != nil {
+			t.Fatal(err)
+		}
+		kinds = append(kinds, gvk.Kind)
+	}
+	expected := []string{"Ingress", "ScaledObject", "Deployment", "StatefulSet", "ReplicaSet", "CronJob"}
+	if len(kinds) != len(expected) {
+		t.Fatalf("expected %v to be watched, got %v", expected, kinds)
+	}
+	for index, kind := range expected {
+		if kinds[index] != kind {
+			t.Fatalf("expected %v to be watched, got %v", expected, kinds)
+		}
+	}
+}
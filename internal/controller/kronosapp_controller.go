@@ -18,20 +18,28 @@ package kronosapp
 
 import (
 	"context"
-	// "fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // KronosAppReconciler reconciles a KronosApp object
@@ -39,11 +47,27 @@ type KronosAppReconciler struct {
 	client.Client
 	Scheme  *runtime.Scheme
 	Metrics Metrics
+	// StateStore selects where the original state of the resources put to
+	// sleep is kept: StateStoreSecret, StateStoreConfigMap or
+	// StateStoreAnnotations.
+	StateStore string
+	Recorder   record.EventRecorder
+	// DryRun makes every KronosApp behave as if its spec.dryRun was set.
+	DryRun bool
+	// APIReader reads from the API server the objects that are not cached:
+	// the documents of the state stores not selected, and the objects listed
+	// by the annotation store.
+	APIReader client.Reader
+
+	// migrated holds the UIDs of the KronosApps whose state was migrated from
+	// the stores not selected since the operator started.
+	migrated sync.Map
 }
 
 //+kubebuilder:rbac:groups=core.wecraft.tn,resources=kronosapps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.wecraft.tn,resources=kronosapps/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.wecraft.tn,resources=kronosapps/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -60,25 +84,47 @@ func (r *KronosAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	if err != nil {
 		l.Error(err, "Unable to fetch KronosApp")
 		if apierrors.IsNotFound(err) {
-			r.Metrics.ScheduleInfo.Delete(prometheus.Labels{
-				"name":      req.Name,
-				"namespace": req.Namespace,
-			})
+			r.deleteMetrics(req)
 		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	return r.reconcileKronosApp(ctx, req, kronosApp, getSecretName(req.Name), req.Namespace)
+}
+
+// reconcileKronosApp applies the schedule of a KronosApp or a
+// ClusterKronosApp, recording the state of its included objects in the secret
+// secretName of secretNamespace. Included objects handled by another
+// KronosApp are left untouched and reported as skipped.
+func (r *KronosAppReconciler) reconcileKronosApp(ctx context.Context, req ctrl.Request, kronosApp v1alpha1.KronosAppObject, secretName, secretNamespace string) (ctrl.Result, error) {
+	l := log.Log
+	spec := kronosApp.GetSpec()
+	if !kronosApp.GetDeletionTimestamp().IsZero() {
+		return r.finalizeKronosApp(ctx, req, kronosApp, secretName, secretNamespace)
+	}
+	err := r.addFinalizer(ctx, kronosApp)
+	if err != nil {
+		l.Error(err, "Adding Finalizer")
 		return ctrl.Result{}, err
 	}
-	secretName := getSecretName(req.Name)
-	secret, err := r.getSecret(ctx, secretName, req.Namespace)
+	if spec.Suspend {
+		return r.suspendKronosApp(ctx, req, kronosApp, secretName, secretNamespace)
+	}
+	dryRun := r.isDryRun(kronosApp)
+	var store StateStore
+	created := false
+	if dryRun {
+		store, err = r.openDryRunStateStore(ctx, kronosApp, secretName, secretNamespace)
+	} else {
+		store, created, err = r.openStateStore(ctx, kronosApp, secretName, secretNamespace)
+	}
 	if err != nil {
-		err := checkIfSecretWasCreatedPreviously(kronosApp, req.Name)
-		if err != nil {
-			l.Error(err, "Fetching Secret Records")
-		}
-		err = r.createSecret(ctx, secretName, req.Namespace)
-		if err != nil {
-			l.Error(err, "Creating Secret")
-		}
-		l.Info("secret created", "secret name", secretName, "namespace", req.Namespace)
+		l.Error(err, "Opening State Store")
+		r.setConditions(ctx, kronosApp,
+			newCondition(v1alpha1.ConditionStateStoreHealthy, metav1.ConditionFalse, "StateStoreUnavailable", err.Error()),
+			notReady("StateStoreUnavailable", err))
+		return ctrl.Result{}, err
+	}
+	if created {
 		err = r.registerSecret(ctx, secretName, kronosApp)
 		if err != nil {
 			l.Error(err, "Updating Created Secrets")
@@ -87,14 +133,34 @@ func (r *KronosAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			Requeue: true,
 		}, nil
 	}
-	l.Info("secret found", "secret", secret.Name)
-	schedule, err := NewSleepSchedule(kronosApp.Spec.StartSleep, kronosApp.Spec.EndSleep, kronosApp.Spec.WeekDays, kronosApp.Spec.TimeZone, kronosApp.Spec.Holidays)
+	conditions := []metav1.Condition{
+		newCondition(v1alpha1.ConditionSuspended, metav1.ConditionFalse, "ScheduleActive", "The schedule is applied"),
+	}
+	err = store.Verify()
+	if err != nil {
+		l.Error(err, "Verifying State Store Checksum")
+		r.recordEvent(kronosApp, corev1.EventTypeWarning, ReasonStateStoreTampered, "%s", err.Error())
+		// Nothing is transitioned nor saved, so that the checksum is not
+		// stamped over the tampered records.
+		r.setConditions(ctx, kronosApp, append(conditions,
+			newCondition(v1alpha1.ConditionStateStoreHealthy, metav1.ConditionFalse, "ChecksumMismatch", err.Error()),
+			notReady("ChecksumMismatch", err))...)
+		return ctrl.Result{}, err
+	}
+	conditions = append(conditions, newCondition(v1alpha1.ConditionStateStoreHealthy, metav1.ConditionTrue, "StateStoreVerified", "The state store matches its checksum"))
+	schedule, err := NewSleepSchedule(spec.StartSleep, spec.EndSleep, spec.WeekDays, spec.TimeZone, spec.Holidays)
 	if err != nil {
 		l.Error(err, "Creating Schedule")
+		r.setConditions(ctx, kronosApp,
+			newCondition(v1alpha1.ConditionScheduleValid, metav1.ConditionFalse, "InvalidSchedule", err.Error()),
+			notReady("InvalidSchedule", err))
 		return ctrl.Result{}, err
 	}
 	isHoliday, ok, additionRequeueDuration, err := IsTimeToSleep(*schedule, kronosApp)
 	if err != nil {
+		r.setConditions(ctx, kronosApp,
+			newCondition(v1alpha1.ConditionScheduleValid, metav1.ConditionFalse, "InvalidSchedule", err.Error()),
+			notReady("InvalidSchedule", err))
 		return ctrl.Result{}, err
 	}
 	var requeueTime time.Duration
@@ -105,84 +171,190 @@ func (r *KronosAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		requeueTime = getRequeueTime(*schedule)
 		l.Info("Getting Requeue Time", "requeue time", formatDuration(requeueTime))
 	}
+	requeueTime = boundRequeueTime(*schedule, kronosApp, requeueTime)
 
-	inclusive, err := ValidateIncludedObjects(kronosApp.Spec.IncludedObjects)
+	inclusive, err := ValidateIncludedObjects(spec.IncludedObjects)
 	if err != nil {
 		l.Error(err, "Validating Included Objects")
+		r.setConditions(ctx, kronosApp,
+			newCondition(v1alpha1.ConditionScheduleValid, metav1.ConditionFalse, "InvalidIncludedObjects", err.Error()),
+			notReady("InvalidIncludedObjects", err))
 		return ctrl.Result{}, err
 	}
-	includedObjects, err := FetchIncludedObjects(ctx, r.Client, kronosApp.Spec.IncludedObjects, inclusive)
+	conditions = append(conditions, newCondition(v1alpha1.ConditionScheduleValid, metav1.ConditionTrue, "ScheduleValid", "The schedule and the included objects are valid"))
+	includedObjects, err := FetchIncludedObjects(ctx, r.Client, spec.IncludedObjects, inclusive)
 	if err != nil {
 		l.Error(err, "Fetching Included Objects")
+		r.setConditions(ctx, kronosApp, notReady("FetchFailed", err))
 		return ctrl.Result{}, err
 	}
-	currentStatus := kronosApp.Status
-	newStatus := kronosApp.GetNewKronosAppStatus(ok, isHoliday, schedule.now.Add(requeueTime), includedObjects.GetObjectsTotalCount())
-	err = kronosApp.SetNewKronosAppStatus(ctx, r.Client, newStatus)
+	claims, err := getClaims(ctx, r.Client, kronosApp, &includedObjects)
 	if err != nil {
-		l.Error(err, "Updating KronosApp Status")
+		l.Error(err, "Fetching Objects Claimed By KronosApps")
+		r.setConditions(ctx, kronosApp, notReady("FetchFailed", err))
 		return ctrl.Result{}, err
 	}
-	r.deleteOldMetrics(req, currentStatus)
-	r.exportAdditionalMetrics(req, newStatus, ok)
-	l.Info("isTimeToSleep", "execute", ok, "error", err)
+	arbitrateClaims(kronosApp, &includedObjects, claims)
+	currentStatus := *kronosApp.GetStatus()
+	newStatus := spec.GetNewKronosAppStatus(ok, isHoliday, schedule.now, schedule.now.Add(requeueTime), includedObjects.GetObjectsTotalCount())
+	newStatus.CreatedSecrets = currentStatus.CreatedSecrets
+	newStatus.SkippedResources = includedObjects.Skipped
+	newStatus.Conflicts = includedObjects.Conflicts
+	newStatus.Conditions = currentStatus.DeepCopy().Conditions
+	newStatus.DryRun = dryRun
+	l.Info("isTimeToSleep", "execute", ok)
+	r.recordScheduleEvents(kronosApp, currentStatus, newStatus)
+	r.recordTransitionStarted(kronosApp, currentStatus, ok)
+
+	objectClient := r.getObjectClient(kronosApp)
+	t := newTransition(currentStatus.Resources, time.Now())
 	if ok {
-		inclusive, err := ValidateIncludedObjects(kronosApp.Spec.IncludedObjects)
+		conditions = append(conditions, newCondition(v1alpha1.ConditionAsleep, metav1.ConditionTrue, newStatus.Reason, getAsleepMessage(dryRun, "The included objects are asleep")))
+		includedObjects.SleepingService = spec.SleepingService
+		includedObjects.WakeTime = schedule.now.Add(requeueTime)
+		includedObjects.DriftPolicy = spec.DriftPolicy
+		l.Info("Fetching Included Resources", "Total Resources", includedObjects.GetObjectsTotalCount(), "Included Resources", includedObjects.GetObjectsCount())
+		t, err = putIncludedObjectsToSleep(ctx, objectClient, store, includedObjects, currentStatus.Resources)
+		r.reportDrift(req, kronosApp, t.drifted)
 		if err != nil {
-			l.Error(err, "Validating Included Objects")
-			return ctrl.Result{}, err
+			l.Error(err, "Putting Included Objects To Sleep")
 		}
-		includedObjects, err := FetchIncludedObjects(ctx, r.Client, kronosApp.Spec.IncludedObjects, inclusive)
+	} else {
+		conditions = append(conditions, newCondition(v1alpha1.ConditionAsleep, metav1.ConditionFalse, newStatus.Reason, getAsleepMessage(dryRun, "The included objects are awake")))
+		err = restoreResources(ctx, objectClient, store, t)
 		if err != nil {
-			l.Error(err, "Fetching Included Objects")
-			return ctrl.Result{}, err
+			l.Error(err, "Waking Up Resources")
 		}
-		l.Info("Fetching Included Resources", "Total Resources", includedObjects.GetObjectsTotalCount(), "Included Resources", includedObjects.GetObjectsCount())
-		failedObjects, err := putIncludedObjectsToSleep(ctx, r.Client, secret, includedObjects)
-		if len(failedObjects) != 0 {
-			logFailedObjects(failedObjects, l)
-			return ctrl.Result{}, nil
+	}
+
+	switch {
+	case err != nil:
+		conditions = append(conditions,
+			newCondition(v1alpha1.ConditionDegraded, metav1.ConditionTrue, "TransitionFailed", err.Error()),
+			notReady("TransitionFailed", err))
+	case len(t.failed) != 0:
+		failedErr := t.err()
+		conditions = append(conditions,
+			newCondition(v1alpha1.ConditionDegraded, metav1.ConditionTrue, "PartialFailure", failedErr.Error()),
+			notReady("PartialFailure", failedErr))
+	default:
+		conditions = append(conditions,
+			newCondition(v1alpha1.ConditionDegraded, metav1.ConditionFalse, "TransitionSucceeded", "Every included object was handled"),
+			newCondition(v1alpha1.ConditionReady, metav1.ConditionTrue, "TransitionSucceeded", "The schedule is applied to every included object"))
+	}
+	newStatus.Resources, newStatus.ResourcesOmitted = t.getResourceStatuses(&includedObjects)
+	setStatusConditions(&newStatus, kronosApp.GetGeneration(), conditions...)
+	statusErr := v1alpha1.SetNewKronosAppStatus(ctx, r.Client, kronosApp, newStatus)
+	if statusErr != nil {
+		l.Error(statusErr, "Updating KronosApp Status")
+		return ctrl.Result{}, statusErr
+	}
+	r.recordTransitionEvents(kronosApp, currentStatus, ok, t, err)
+	r.deleteOldMetrics(req, currentStatus)
+	r.exportAdditionalMetrics(req, newStatus, ok)
+
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(t.failed) != 0 {
+		logFailedObjects(t.failed, l)
+		// The objects that could not be handled are retried as soon as
+		// their backoff expires, unless the schedule comes first.
+		retryTime := t.getRetryRequeueTime()
+		if retryTime < requeueTime {
+			requeueTime = retryTime
+		}
+	}
+	return ctrl.Result{
+		RequeueAfter: requeueTime,
+	}, nil
+}
+
+// getAsleepMessage returns the message of the Asleep condition, telling in dry
+// run that the included objects are left as they are.
+func getAsleepMessage(dryRun bool, message string) string {
+	if dryRun {
+		return message + " in dry run, no object being changed"
+	}
+	return message
+}
+
+// restoreResources wakes up the resources recorded in the store, if any, and
+// purges it.
+func restoreResources(ctx context.Context, Client client.Client, store StateStore, t *transition) error {
+	empty, err := isStateStoreEmpty(ctx, store)
+	if err != nil || empty {
+		return err
+	}
+	asleep, err := wakeUpResources(ctx, Client, store, t)
+	if err != nil {
+		return err
+	}
+	if len(asleep) == 0 {
+		return store.Purge(ctx)
+	}
+	// The resources still asleep stay recorded until they are woken up.
+	for _, kind := range getAllKinds() {
+		var resources []object.ResourceInt
+		for _, resource := range asleep {
+			if resource.GetKind() == kind {
+				resources = append(resources, resource)
+			}
 		}
+		recorded, err := store.Load(ctx, kind)
 		if err != nil {
-			l.Error(err, "Putting Included Objects To Sleep")
-			return ctrl.Result{}, err
+			return err
 		}
-
-		return ctrl.Result{
-			RequeueAfter: requeueTime,
-		}, nil
-	} else {
-		err := CheckIfSecretContainsData(secret)
+		err = WriteChanges(ctx, store, resources, kind, len(recorded) != 0)
 		if err != nil {
-			l.Error(err, "Restoring Replicas")
-		} else {
-			err = WakeUpResources(ctx, r.Client, secret)
-			if err != nil {
-				l.Error(err, "Waking Up Resources")
-				return ctrl.Result{}, err
-			}
-			err = purgeSecretData(ctx, r.Client, secret)
-			if err != nil {
-				l.Error(err, "Purging Secret's Data")
-				return ctrl.Result{}, err
-			}
+			return err
 		}
-
-		return ctrl.Result{
-			RequeueAfter: requeueTime,
-		}, nil
 	}
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *KronosAppReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	pred := predicate.GenerationChangedPredicate{}
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.KronosApp{}).
-		WithEventFilter(pred).
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.KronosApp{}, builder.WithPredicates(pred))
+	if document := r.getOwnedStateDocument(); document != nil {
+		b = b.Owns(document, builder.WithPredicates(stateDocumentPredicate()))
+	}
+	b = b.Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.findKronosAppsForNamespace), builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(&v1alpha1.KronosApp{}, handler.EnqueueRequestsFromMapFunc(r.findOverlappingKronosApps), builder.WithPredicates(pred)).
+		Watches(&v1alpha1.ClusterKronosApp{}, handler.EnqueueRequestsFromMapFunc(r.findOverlappingKronosApps), builder.WithPredicates(pred))
+	return watchManagedObjects(b, mgr.GetRESTMapper(), "KronosApp", r.findKronosAppsForObject).
 		Complete(r)
 }
 
+// findOverlappingKronosApps enqueues the KronosApps that may include the same
+// objects as a changed KronosApp or ClusterKronosApp, as the change may create
+// or resolve conflicts over them. Updates map both the old and the new
+// version, so that the objects released by a change are claimed again.
+func (r *KronosAppReconciler) findOverlappingKronosApps(ctx context.Context, changed client.Object) []reconcile.Request {
+	l := log.FromContext(ctx)
+	changedKronosApp, ok := changed.(v1alpha1.KronosAppObject)
+	if !ok {
+		return nil
+	}
+	kronosApps := &v1alpha1.KronosAppList{}
+	err := r.List(ctx, kronosApps)
+	if err != nil {
+		l.Error(err, "Listing KronosApps")
+		return nil
+	}
+	var requests []reconcile.Request
+	for index := range kronosApps.Items {
+		kronosApp := &kronosApps.Items[index]
+		if getClaimantName(kronosApp) == getClaimantName(changedKronosApp) || !kronosApp.Spec.MayOverlap(*changedKronosApp.GetSpec()) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(kronosApp)})
+	}
+	return requests
+}
+
 func (r *KronosAppReconciler) getKronosApp(ctx context.Context, req ctrl.Request) (*v1alpha1.KronosApp, error) {
 	kronosApp := &v1alpha1.KronosApp{}
 	err := r.Get(ctx, req.NamespacedName, kronosApp)
@@ -193,6 +365,9 @@ func (r *KronosAppReconciler) getKronosApp(ctx context.Context, req ctrl.Request
 }
 
 func (r *KronosAppReconciler) exportAdditionalMetrics(req ctrl.Request, newStatus v1alpha1.KronosAppStatus, isTimeToSleep bool) {
+	if !r.Metrics.isSet() {
+		return
+	}
 	var value float64
 	if isTimeToSleep {
 		value = 0
@@ -204,26 +379,60 @@ func (r *KronosAppReconciler) exportAdditionalMetrics(req ctrl.Request, newStatu
 		"namespace":         req.Namespace,
 		"status":            newStatus.Status,
 		"reason":            newStatus.Reason,
-		"handled_resources": newStatus.HandledResources,
+		"handled_resources": strconv.Itoa(int(newStatus.HandledResources)),
 		"next_operation":    newStatus.NextOperation,
 	}).Set(value)
 	r.Metrics.ScheduleInfo.With(prometheus.Labels{
 		"name":      req.Name,
 		"namespace": req.Namespace,
 	}).Set(value)
+	var suspended float64
+	if newStatus.Status == v1alpha1.StatusSuspended {
+		suspended = 1
+	}
+	r.Metrics.Suspended.With(prometheus.Labels{
+		"name":      req.Name,
+		"namespace": req.Namespace,
+	}).Set(suspended)
 }
 
 func (r *KronosAppReconciler) deleteOldMetrics(req ctrl.Request, oldStatus v1alpha1.KronosAppStatus) {
+	if !r.Metrics.isSet() {
+		return
+	}
 	r.Metrics.InDepthScheduleInfo.Delete(prometheus.Labels{
 		"name":              req.Name,
 		"namespace":         req.Namespace,
 		"status":            oldStatus.Status,
 		"reason":            oldStatus.Reason,
-		"handled_resources": oldStatus.HandledResources,
+		"handled_resources": strconv.Itoa(int(oldStatus.HandledResources)),
 		"next_operation":    oldStatus.NextOperation,
 	})
 }
 
+// reportDrift records an Event and counts each object found changed by
+// anything but the controller while asleep.
+func (r *KronosAppReconciler) reportDrift(req ctrl.Request, kronosApp v1alpha1.KronosAppObject, driftedObjects map[string][]string) {
+	policy := kronosApp.GetSpec().DriftPolicy
+	if policy == "" {
+		policy = v1alpha1.DriftPolicyResleep
+	}
+	for kind, names := range driftedObjects {
+		for _, name := range names {
+			r.recordEvent(kronosApp, corev1.EventTypeWarning, ReasonDriftDetected, "%s %s was changed while asleep, applying the %s policy", kind, name, policy)
+			if !r.Metrics.isSet() {
+				continue
+			}
+			r.Metrics.DriftDetected.With(prometheus.Labels{
+				"name":      req.Name,
+				"namespace": req.Namespace,
+				"kind":      kind,
+				"policy":    policy,
+			}).Inc()
+		}
+	}
+}
+
 func logFailedObjects(failedObjects map[string][]string, l logr.Logger) {
-	l.Info("Logging Failed Sleep", "deployments", failedObjects["Deployment"], "StatefulSets", failedObjects["StatefulSets"], "CronJobs", failedObjects["CronJobs"])
+	l.Info("Logging Failed Objects", "failed objects", failedObjects)
 }
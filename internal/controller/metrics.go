@@ -2,12 +2,15 @@ package kronosapp
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 type Metrics struct {
 	ScheduleInfo        *prometheus.GaugeVec
 	InDepthScheduleInfo *prometheus.GaugeVec
+	DriftDetected       *prometheus.CounterVec
+	Suspended           *prometheus.GaugeVec
 }
 
 func RegisterMetrics() Metrics {
@@ -20,6 +23,14 @@ func RegisterMetrics() Metrics {
 			Name: "indepth_schedule_info",
 			Help: "Current schedule information",
 		}, []string{"name", "namespace", "status", "reason", "handled_resources", "next_operation"}),
+		DriftDetected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "drift_detected_total",
+			Help: "Number of objects found changed by anything but the controller while asleep",
+		}, []string{"name", "namespace", "kind", "policy"}),
+		Suspended: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "schedule_suspended",
+			Help: "Whether the schedule is suspended",
+		}, []string{"name", "namespace"}),
 	}
 	return scheduleInfoMetrics
 }
@@ -28,6 +39,28 @@ func (additionalMetrics Metrics) MustRegister(registry metrics.RegistererGathere
 	registry.MustRegister(
 		additionalMetrics.ScheduleInfo,
 		additionalMetrics.InDepthScheduleInfo,
+		additionalMetrics.DriftDetected,
+		additionalMetrics.Suspended,
 	)
 	return additionalMetrics
 }
+
+// isSet reports whether the metrics were created, a reconciler built without
+// them exporting none.
+func (additionalMetrics Metrics) isSet() bool {
+	return additionalMetrics.ScheduleInfo != nil
+}
+
+// deleteMetrics deletes the metrics exported for a KronosApp that is gone.
+func (r *KronosAppReconciler) deleteMetrics(req ctrl.Request) {
+	if !r.Metrics.isSet() {
+		return
+	}
+	labels := prometheus.Labels{
+		"name":      req.Name,
+		"namespace": req.Namespace,
+	}
+	r.Metrics.ScheduleInfo.Delete(labels)
+	r.Metrics.Suspended.Delete(labels)
+	r.Metrics.DriftDetected.DeletePartialMatch(labels)
+}
@@ -0,0 +1,39 @@
+package kronosapp
+
+import (
+	"testing"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestMetricsWithoutRegistration(t *testing.T) {
+	r := &KronosAppReconciler{}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+	r.exportAdditionalMetrics(req, v1alpha1.KronosAppStatus{Status: "Awake"}, false)
+	r.deleteOldMetrics(req, v1alpha1.KronosAppStatus{Status: "Awake"})
+	r.reportDrift(req, &v1alpha1.KronosApp{}, map[string][]string{"Deployment": {"default/web"}})
+	r.deleteMetrics(req)
+}
+
+func TestDeleteMetrics(t *testing.T) {
+	r := &KronosAppReconciler{Metrics: RegisterMetrics()}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+	other := ctrl.Request{NamespacedName: types.NamespacedName{Name: "other", Namespace: "default"}}
+	r.exportAdditionalMetrics(req, v1alpha1.KronosAppStatus{Status: v1alpha1.StatusSuspended}, false)
+	r.exportAdditionalMetrics(other, v1alpha1.KronosAppStatus{Status: "Awake"}, false)
+	r.reportDrift(req, &v1alpha1.KronosApp{}, map[string][]string{"Deployment": {"default/web"}})
+
+	r.deleteMetrics(req)
+	if count := testutil.CollectAndCount(r.Metrics.ScheduleInfo); count != 1 {
+		t.Errorf("expected only the schedule of the other KronosApp to be left, got %d", count)
+	}
+	if count := testutil.CollectAndCount(r.Metrics.Suspended); count != 1 {
+		t.Errorf("expected only the suspension of the other KronosApp to be left, got %d", count)
+	}
+	if count := testutil.CollectAndCount(r.Metrics.DriftDetected); count != 0 {
+		t.Errorf("expected the drift counters to be deleted, got %d", count)
+	}
+}
@@ -0,0 +1,140 @@
+package kronosapp
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+func usesNamespaceSelection(includedObject v1alpha1.IncludedObject) bool {
+	return includedObject.NamespaceSelector != nil || includedObject.NamespaceRef != ""
+}
+
+// namespaceMatches reports whether a namespace is targeted by the namespace
+// selector and the namespace name pattern of an included object.
+func namespaceMatches(includedObject v1alpha1.IncludedObject, namespace *corev1.Namespace) (bool, error) {
+	if namespace.Status.Phase == corev1.NamespaceTerminating {
+		return false, nil
+	}
+	namespaceSelector, err := metav1.LabelSelectorAsSelector(includedObject.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	if includedObject.NamespaceSelector != nil && !namespaceSelector.Matches(labels.Set(namespace.Labels)) {
+		return false, nil
+	}
+	if includedObject.NamespaceRef != "" {
+		namespaceRe, err := regexp.Compile(includedObject.NamespaceRef)
+		if err != nil {
+			return false, err
+		}
+		if !namespaceRe.MatchString(namespace.Name) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// getNamespace returns the namespace of an included object, the default
+// namespace when it was stored without one.
+func getNamespace(includedObject v1alpha1.IncludedObject) string {
+	if includedObject.Namespace == "" {
+		return "default"
+	}
+	return includedObject.Namespace
+}
+
+// resolveNamespaces returns the namespaces an included object applies to:
+// its namespace, or every namespace matching its namespace selector and
+// namespace name pattern when any of them is set.
+func resolveNamespaces(ctx context.Context, Client client.Client, includedObject v1alpha1.IncludedObject) ([]string, error) {
+	if !usesNamespaceSelection(includedObject) {
+		return []string{getNamespace(includedObject)}, nil
+	}
+	namespaceList := &corev1.NamespaceList{}
+	err := Client.List(ctx, namespaceList)
+	if err != nil {
+		return nil, err
+	}
+	var namespaces []string
+	for index := range namespaceList.Items {
+		ok, err := namespaceMatches(includedObject, &namespaceList.Items[index])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			namespaces = append(namespaces, namespaceList.Items[index].Name)
+		}
+	}
+	return namespaces, nil
+}
+
+// findKronosAppsForNamespace enqueues the KronosApps selecting a namespace
+// so that namespaces created or relabelled after them are handled promptly.
+func (r *KronosAppReconciler) findKronosAppsForNamespace(ctx context.Context, namespace client.Object) []reconcile.Request {
+	l := log.FromContext(ctx)
+	ns, ok := namespace.(*corev1.Namespace)
+	if !ok {
+		return nil
+	}
+	kronosApps := &v1alpha1.KronosAppList{}
+	err := r.List(ctx, kronosApps)
+	if err != nil {
+		l.Error(err, "Listing KronosApps", "namespace", ns.Name)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, kronosApp := range kronosApps.Items {
+		if selectsNamespace(kronosApp.Spec.IncludedObjects, ns) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&kronosApp)})
+		}
+	}
+	return requests
+}
+
+// selectsNamespace reports whether any of the included objects selects the
+// namespace through its namespace selector or namespace name pattern.
+func selectsNamespace(includedObjects []v1alpha1.IncludedObject, namespace *corev1.Namespace) bool {
+	for _, includedObject := range includedObjects {
+		if !usesNamespaceSelection(includedObject) {
+			continue
+		}
+		matches, err := namespaceMatches(includedObject, namespace)
+		if err == nil && matches {
+			return true
+		}
+	}
+	return false
+}
+
+// findClusterKronosAppsForNamespace enqueues the ClusterKronosApps selecting a
+// namespace.
+func (r *ClusterKronosAppReconciler) findClusterKronosAppsForNamespace(ctx context.Context, namespace client.Object) []reconcile.Request {
+	l := log.FromContext(ctx)
+	ns, ok := namespace.(*corev1.Namespace)
+	if !ok {
+		return nil
+	}
+	clusterKronosApps := &v1alpha1.ClusterKronosAppList{}
+	err := r.List(ctx, clusterKronosApps)
+	if err != nil {
+		l.Error(err, "Listing ClusterKronosApps", "namespace", ns.Name)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, clusterKronosApp := range clusterKronosApps.Items {
+		if selectsNamespace(clusterKronosApp.Spec.IncludedObjects, ns) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusterKronosApp)})
+		}
+	}
+	return requests
+}
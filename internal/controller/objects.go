@@ -2,24 +2,60 @@ package kronosapp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/KronosOrg/kronos-core/api/v1alpha1"
 	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
-	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;replicasets,verbs=get;list;watch;update
-// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;replicasets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments/scale;statefulsets/scale;replicasets/scale,verbs=get;update;patch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=keda.sh,resources=scaledobjects;scaledjobs,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;update;patch
 
 type ObjectList struct {
-	Deployments  *appsv1.DeploymentList
-	StatefulSets *appsv1.StatefulSetList
-	ReplicaSets  *appsv1.ReplicaSetList
-	CronJobs     *batchv1.CronJobList
+	Deployments    *appsv1.DeploymentList
+	StatefulSets   *appsv1.StatefulSetList
+	ReplicaSets    *appsv1.ReplicaSetList
+	CronJobs       *batchv1.CronJobList
+	Applications   *unstructured.UnstructuredList
+	Kustomizations *unstructured.UnstructuredList
+	HelmReleases   *unstructured.UnstructuredList
+	ScaledObjects  *unstructured.UnstructuredList
+	ScaledJobs     *unstructured.UnstructuredList
+	Ingresses      *networkingv1.IngressList
+	Skipped        []v1alpha1.SkippedResource
+	// SleepReplicas holds the sleep replicas set by the included objects,
+	// keyed by kind, namespace and name.
+	SleepReplicas map[string]int32
+	// SleepingService and WakeTime describe where the included Ingresses are
+	// routed while asleep.
+	SleepingService *v1alpha1.SleepingService
+	WakeTime        time.Time
+	// Owner identifies the KronosApp putting the objects to sleep, and
+	// Conflicts the objects it shares with other KronosApps.
+	Owner     string
+	Conflicts []v1alpha1.ResourceConflict
+	// DriftPolicy tells what is done with the objects found awake while
+	// recorded as asleep.
+	DriftPolicy string
 }
 
 func (objectList *ObjectList) GetObjectsNames() map[string][]string {
@@ -36,6 +72,24 @@ func (objectList *ObjectList) GetObjectsNames() map[string][]string {
 	if objectList.ReplicaSets != nil {
 		objectNames["ReplicaSets"] = object.GetReplicaSetListNames(objectList.ReplicaSets)
 	}
+	if objectList.Applications != nil {
+		objectNames["Applications"] = object.GetUnstructuredListNames(objectList.Applications)
+	}
+	if objectList.Kustomizations != nil {
+		objectNames["Kustomizations"] = object.GetUnstructuredListNames(objectList.Kustomizations)
+	}
+	if objectList.HelmReleases != nil {
+		objectNames["HelmReleases"] = object.GetUnstructuredListNames(objectList.HelmReleases)
+	}
+	if objectList.ScaledObjects != nil {
+		objectNames["ScaledObjects"] = object.GetUnstructuredListNames(objectList.ScaledObjects)
+	}
+	if objectList.ScaledJobs != nil {
+		objectNames["ScaledJobs"] = object.GetUnstructuredListNames(objectList.ScaledJobs)
+	}
+	if objectList.Ingresses != nil {
+		objectNames["Ingresses"] = object.GetIngressListNames(objectList.Ingresses)
+	}
 	return objectNames
 }
 
@@ -53,24 +107,337 @@ func (objectList *ObjectList) GetObjectsCount() map[string]int {
 	if objectList.ReplicaSets != nil {
 		objectCount["ReplicaSets"] = len(objectList.ReplicaSets.Items)
 	}
+	if objectList.Applications != nil {
+		objectCount["Applications"] = len(objectList.Applications.Items)
+	}
+	if objectList.Kustomizations != nil {
+		objectCount["Kustomizations"] = len(objectList.Kustomizations.Items)
+	}
+	if objectList.HelmReleases != nil {
+		objectCount["HelmReleases"] = len(objectList.HelmReleases.Items)
+	}
+	if objectList.ScaledObjects != nil {
+		objectCount["ScaledObjects"] = len(objectList.ScaledObjects.Items)
+	}
+	if objectList.ScaledJobs != nil {
+		objectCount["ScaledJobs"] = len(objectList.ScaledJobs.Items)
+	}
+	if objectList.Ingresses != nil {
+		objectCount["Ingresses"] = len(objectList.Ingresses.Items)
+	}
 	return objectCount
 }
 
 func (objectList *ObjectList) GetObjectsTotalCount() int {
 	var count int
-	if objectList.Deployments != nil {
-		count += len(objectList.Deployments.Items)
+	for _, kindCount := range objectList.GetObjectsCount() {
+		count += kindCount
 	}
-	if objectList.StatefulSets != nil {
-		count += len(objectList.StatefulSets.Items)
+	return count
+}
+
+func (objectList *ObjectList) ContainsKind(kind string) bool {
+	k, ok := getSupportedKind(kind)
+	return ok && k.lists.contains(objectList)
+}
+
+// GetResources converts the fetched objects of the given kind into the
+// resources recorded in the state store, holding their current state.
+func (objectList *ObjectList) GetResources(kind string) []object.ResourceInt {
+	var resources []object.ResourceInt
+	add := func(item metav1.Object, resource object.ResourceInt) {
+		resources = append(resources, object.SetIdentity(resource, item))
+	}
+	switch kind {
+	case "Deployment":
+		for index := range objectList.Deployments.Items {
+			item := &objectList.Deployments.Items[index]
+			add(item, objectList.newReplicaResource(kind, item, *item.Spec.Replicas))
+		}
+	case "StatefulSet":
+		for index := range objectList.StatefulSets.Items {
+			item := &objectList.StatefulSets.Items[index]
+			add(item, objectList.newReplicaResource(kind, item, *item.Spec.Replicas))
+		}
+	case "ReplicaSet":
+		for index := range objectList.ReplicaSets.Items {
+			item := &objectList.ReplicaSets.Items[index]
+			add(item, objectList.newReplicaResource(kind, item, *item.Spec.Replicas))
+		}
+	case "CronJob":
+		for index := range objectList.CronJobs.Items {
+			item := &objectList.CronJobs.Items[index]
+			add(item, object.NewStatusResource(kind, item.Name, item.Namespace, object.IsSuspended(item.Spec.Suspend)))
+		}
+	case "Application":
+		for index := range objectList.Applications.Items {
+			add(&objectList.Applications.Items[index], object.NewSyncPolicyResourceFromUnstructured(objectList.Applications.Items[index]))
+		}
+	case "Kustomization":
+		for index := range objectList.Kustomizations.Items {
+			add(&objectList.Kustomizations.Items[index], object.NewStatusResourceFromUnstructured(objectList.Kustomizations.Items[index]))
+		}
+	case "HelmRelease":
+		for index := range objectList.HelmReleases.Items {
+			add(&objectList.HelmReleases.Items[index], object.NewStatusResourceFromUnstructured(objectList.HelmReleases.Items[index]))
+		}
+	case "ScaledObject":
+		for index := range objectList.ScaledObjects.Items {
+			add(&objectList.ScaledObjects.Items[index], object.NewAnnotationResourceFromUnstructured(objectList.ScaledObjects.Items[index]))
+		}
+	case "ScaledJob":
+		for index := range objectList.ScaledJobs.Items {
+			add(&objectList.ScaledJobs.Items[index], object.NewAnnotationResourceFromUnstructured(objectList.ScaledJobs.Items[index]))
+		}
+	case "Ingress":
+		sleepingBackend := objectList.getSleepingBackend()
+		for index := range objectList.Ingresses.Items {
+			item := &objectList.Ingresses.Items[index]
+			add(item, object.NewIngressResource(*item, sleepingBackend, objectList.WakeTime.Format(time.RFC3339)))
+		}
 	}
-	if objectList.CronJobs != nil {
-		count += len(objectList.CronJobs.Items)
+	return resources
+}
+
+// newReplicaResource records the replicas of a workload along with the
+// replicas it keeps while asleep, its sleep-replicas annotation taking
+// precedence over the included object.
+func (objectList *ObjectList) newReplicaResource(kind string, item metav1.Object, replicas int32) object.ReplicaResource {
+	resource := object.NewReplicaResource(kind, item.GetName(), item.GetNamespace(), replicas)
+	resource.SleepReplicas = objectList.SleepReplicas[getObjectKey(kind, item)]
+	sleepReplicas, ok, err := object.GetSleepReplicas(item)
+	if err == nil && ok {
+		resource.SleepReplicas = sleepReplicas
+	}
+	return resource
+}
+
+// merge appends the objects of another list that are not already part of
+// this one.
+func (objectList *ObjectList) merge(other *ObjectList) {
+	for _, k := range supportedKinds {
+		k.lists.merge(objectList, other)
 	}
-	if objectList.ReplicaSets != nil {
-		count += len(objectList.ReplicaSets.Items)
+}
+
+// setSleepReplicas records the sleep replicas of an included object for the
+// objects it designates, unless an earlier included object already did.
+func (objectList *ObjectList) setSleepReplicas(fetched *ObjectList, sleepReplicas int32) {
+	if objectList.SleepReplicas == nil {
+		objectList.SleepReplicas = make(map[string]int32)
+	}
+	fetched.forEachObject(func(kind string, item metav1.Object) {
+		key := getObjectKey(kind, item)
+		if _, ok := objectList.SleepReplicas[key]; !ok {
+			objectList.SleepReplicas[key] = sleepReplicas
+		}
+	})
+}
+
+func (objectList *ObjectList) getSleepingBackend() *networkingv1.IngressBackend {
+	if objectList.SleepingService == nil {
+		return nil
+	}
+	return &networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: objectList.SleepingService.Name,
+			Port: networkingv1.ServiceBackendPort{Number: objectList.SleepingService.Port},
+		},
 	}
-	return count
+}
+
+// getManagedController returns the controller of an object when the
+// controller is itself of a supported kind, in which case the object is left
+// to its controller.
+func getManagedController(item metav1.Object) *metav1.OwnerReference {
+	controller := metav1.GetControllerOf(item)
+	if controller == nil {
+		return nil
+	}
+	kinds := getSupportedObjectsApiVersionAndKind().GetKind(controller.APIVersion)
+	if !IsInArray(kinds, controller.Kind) {
+		return nil
+	}
+	return controller
+}
+
+// filterItems removes the items skipReason returns a reason for, recording
+// them as skipped.
+func filterItems[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T, kind string, skipReason func(kind string, item metav1.Object) string, skipped *[]v1alpha1.SkippedResource) []T {
+	var kept []T
+	for index := range items {
+		item := PT(&items[index])
+		reason := skipReason(kind, item)
+		if reason == "" {
+			kept = append(kept, items[index])
+			continue
+		}
+		*skipped = append(*skipped, v1alpha1.SkippedResource{
+			Kind:      kind,
+			Namespace: item.GetNamespace(),
+			Name:      item.GetName(),
+			Reason:    reason,
+		})
+	}
+	return kept
+}
+
+// filterObjects removes the objects of every kind that skipReason returns a
+// reason for. Lists left empty are dropped.
+func (objectList *ObjectList) filterObjects(skipReason func(kind string, item metav1.Object) string) {
+	for _, k := range supportedKinds {
+		k.lists.filter(objectList, k.kind, skipReason)
+	}
+}
+
+// forEachObject calls fn for every object of the list.
+func (objectList *ObjectList) forEachObject(fn func(kind string, item metav1.Object)) {
+	objectList.filterObjects(func(kind string, item metav1.Object) string {
+		fn(kind, item)
+		return ""
+	})
+}
+
+// skipControlledObjects removes the objects controlled by another supported
+// kind, such as the ReplicaSets of a Deployment: scaling them would fight
+// with their controller, which is handled on its own. Only those whose
+// controller is not itself included are reported as skipped, so that the
+// history of an included Deployment does not fill the status.
+func (objectList *ObjectList) skipControlledObjects() {
+	included := make(map[types.UID]bool)
+	objectList.forEachObject(func(kind string, item metav1.Object) {
+		included[item.GetUID()] = true
+	})
+	silent := make(map[string]bool)
+	reported := len(objectList.Skipped)
+	objectList.filterObjects(func(kind string, item metav1.Object) string {
+		controller := getManagedController(item)
+		if controller == nil {
+			return ""
+		}
+		if included[controller.UID] {
+			silent[getObjectKey(kind, item)] = true
+		}
+		return fmt.Sprintf("controlled by %s/%s", controller.Kind, controller.Name)
+	})
+	skipped := objectList.Skipped[:reported]
+	for _, resource := range objectList.Skipped[reported:] {
+		if !silent[getSkippedResourceKey(resource)] {
+			skipped = append(skipped, resource)
+		}
+	}
+	objectList.Skipped = skipped
+}
+
+func getSkippedResourceKey(skippedResource v1alpha1.SkippedResource) string {
+	return fmt.Sprintf("%s/%s/%s", skippedResource.Kind, skippedResource.Namespace, skippedResource.Name)
+}
+
+// appendNewItems appends the items that are not already part of the list,
+// as several included objects may designate the same object.
+func appendNewItems[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T, newItems []T) []T {
+	existing := make(map[string]bool)
+	for index := range items {
+		item := PT(&items[index])
+		existing[item.GetNamespace()+"/"+item.GetName()] = true
+	}
+	for index := range newItems {
+		item := PT(&newItems[index])
+		if !existing[item.GetNamespace()+"/"+item.GetName()] {
+			items = append(items, newItems[index])
+		}
+	}
+	return items
+}
+
+func (objectList *ObjectList) fetchKind(ctx context.Context, apiVersion, kind string, resource object.Object) error {
+	switch kind {
+	case "Deployment":
+		deployments, err := object.FetchDeployments(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if deployments != nil && len(deployments.Items) > 0 {
+			if objectList.Deployments == nil {
+				objectList.Deployments = &appsv1.DeploymentList{}
+			}
+			objectList.Deployments.Items = appendNewItems(objectList.Deployments.Items, deployments.Items)
+		}
+	case "StatefulSet":
+		statefulsets, err := object.FetchStatefulsets(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if statefulsets != nil && len(statefulsets.Items) > 0 {
+			if objectList.StatefulSets == nil {
+				objectList.StatefulSets = &appsv1.StatefulSetList{}
+			}
+			objectList.StatefulSets.Items = appendNewItems(objectList.StatefulSets.Items, statefulsets.Items)
+		}
+	case "ReplicaSet":
+		replicasets, err := object.FetchReplicaSets(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if replicasets != nil && len(replicasets.Items) > 0 {
+			if objectList.ReplicaSets == nil {
+				objectList.ReplicaSets = &appsv1.ReplicaSetList{}
+			}
+			objectList.ReplicaSets.Items = appendNewItems(objectList.ReplicaSets.Items, replicasets.Items)
+		}
+	case "CronJob":
+		cronjobs, err := object.FetchCronjobs(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if cronjobs != nil && len(cronjobs.Items) > 0 {
+			if objectList.CronJobs == nil {
+				objectList.CronJobs = &batchv1.CronJobList{}
+			}
+			objectList.CronJobs.Items = appendNewItems(objectList.CronJobs.Items, cronjobs.Items)
+		}
+	case "Ingress":
+		ingresses, err := object.FetchIngresses(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if ingresses != nil && len(ingresses.Items) > 0 {
+			if objectList.Ingresses == nil {
+				objectList.Ingresses = &networkingv1.IngressList{}
+			}
+			objectList.Ingresses.Items = appendNewItems(objectList.Ingresses.Items, ingresses.Items)
+		}
+	default:
+		if !isCustomResourceApiVersion(apiVersion) {
+			return nil
+		}
+		objects, err := object.FetchUnstructured(ctx, resource, apiVersion, kind)
+		if err != nil {
+			return err
+		}
+		if objects != nil && len(objects.Items) > 0 {
+			list := objectList.getUnstructured(kind)
+			if *list == nil {
+				*list = &unstructured.UnstructuredList{}
+			}
+			(*list).Items = appendNewItems((*list).Items, objects.Items)
+		}
+	}
+	return nil
+}
+
+func (objectList *ObjectList) getUnstructured(kind string) **unstructured.UnstructuredList {
+	k, ok := getSupportedKind(kind)
+	if !ok || !k.isCustomResource() {
+		return new(*unstructured.UnstructuredList)
+	}
+	return k.unstructuredList(objectList)
 }
 
 type APIVersionKindMap struct {
@@ -121,23 +488,6 @@ func (m *APIVersionKindMap) KindExists(kind string) bool {
 	return false
 }
 
-func getSupportedObjectsApiVersionAndKind() *APIVersionKindMap {
-	kindToAPIVersion := NewAPIVersionKindMap(map[string][]string{
-		"apps/v1":  {"Deployment", "StatefulSet", "ReplicaSet"},
-		"batch/v1": {"CronJob"},
-	})
-	return kindToAPIVersion
-}
-
-func getAllKinds() []string {
-	var allKinds []string
-	var kindToAPIVersion = getSupportedObjectsApiVersionAndKind()
-	for _, apiKinds := range kindToAPIVersion.Mapping {
-		allKinds = append(allKinds, apiKinds...)
-	}
-	return allKinds
-}
-
 func validateIncludedObject(includedObject v1alpha1.IncludedObject, supportedObjectsApiVersion *APIVersionKindMap) (bool, bool, error) {
 	var extractedKind []string
 	isApiVersionInclusive := true
@@ -181,148 +531,90 @@ func ValidateIncludedObjects(includedObjects []v1alpha1.IncludedObject) (map[int
 	return inclusive, nil
 }
 
-func FetchAndFilter(ctx context.Context, Client client.Client, objectList *ObjectList, apiVersion, kind, includeRef, excludeRef, namespace string) error {
-	var err error
+// getKindsToFetch resolves the kinds designated by an included object.
+func getKindsToFetch(apiVersion, kind string) []string {
 	switch apiVersion {
 	case "*":
-		{
-			deployments, err := object.FetchDeployments(ctx, Client, includeRef, excludeRef, namespace)
-			if err != nil {
-				return err
-			}
-			if len(deployments.Items) > 0 {
-				objectList.Deployments = deployments
-			}
-			statefulsets, err := object.FetchStatefulsets(ctx, Client, includeRef, excludeRef, namespace)
-			if err != nil {
-				return err
-			}
-			if len(statefulsets.Items) > 0 {
-				objectList.StatefulSets = statefulsets
-			}
-			cronjobs, err := object.FetchCronjobs(ctx, Client, includeRef, excludeRef, namespace)
-			if err != nil {
-				return err
-			}
-			if len(cronjobs.Items) > 0 {
-				objectList.CronJobs = cronjobs
-			}
-			replicasets, err := object.FetchReplicaSets(ctx, Client, includeRef, excludeRef, namespace)
-			if err != nil {
-				return err
-			}
-			if len(replicasets.Items) > 0 {
-				objectList.ReplicaSets = replicasets
-			}
-		}
-	case "apps/v1":
-		switch kind {
-		case "Deployment":
-			{
-				deployments, err := object.FetchDeployments(ctx, Client, includeRef, excludeRef, namespace)
-				if err != nil {
-					return err
-				}
-				if len(deployments.Items) > 0 {
-					objectList.Deployments = deployments
-				}
-			}
-		case "StatefulSet":
-			{
-				statefulsets, err := object.FetchStatefulsets(ctx, Client, includeRef, excludeRef, namespace)
-				if err != nil {
-					return err
-				}
-				if len(statefulsets.Items) > 0 {
-					objectList.StatefulSets = statefulsets
-				}
-			}
-		case "ReplicaSet":
-			{
-				replicasets, err := object.FetchReplicaSets(ctx, Client, includeRef, excludeRef, namespace)
-				if err != nil {
-					return err
-				}
-				if len(replicasets.Items) > 0 {
-					objectList.ReplicaSets = replicasets
-				}
-			}
-		case "*":
-			{
-				deployments, err := object.FetchDeployments(ctx, Client, includeRef, excludeRef, namespace)
-				if err != nil {
-					return err
-				}
-				if len(deployments.Items) > 0 {
-					objectList.Deployments = deployments
-				}
-				statefulsets, err := object.FetchStatefulsets(ctx, Client, includeRef, excludeRef, namespace)
-				if err != nil {
-					return err
-				}
-				if len(statefulsets.Items) > 0 {
-					objectList.StatefulSets = statefulsets
-				}
-				replicasets, err := object.FetchReplicaSets(ctx, Client, includeRef, excludeRef, namespace)
-				if err != nil {
-					return err
-				}
-				if len(replicasets.Items) > 0 {
-					objectList.ReplicaSets = replicasets
-				}
-			}
-		}
-
+		return []string{"Deployment", "StatefulSet", "CronJob", "ReplicaSet"}
 	case "batch/v1":
-		cronjobs, err := object.FetchCronjobs(ctx, Client, includeRef, excludeRef, namespace)
+		return []string{"CronJob"}
+	case "networking.k8s.io/v1":
+		return []string{"Ingress"}
+	}
+	// Custom resources are only handled when their apiVersion is explicitly
+	// included, as their CRDs are not necessarily installed.
+	if kind == "*" {
+		return getSupportedObjectsApiVersionAndKind().GetKind(apiVersion)
+	}
+	return []string{kind}
+}
+
+func FetchAndFilter(ctx context.Context, objectList *ObjectList, apiVersion, kind string, resource object.Object) error {
+	for _, kindToFetch := range getKindsToFetch(apiVersion, kind) {
+		err := objectList.fetchKind(ctx, apiVersion, kindToFetch, resource)
 		if err != nil {
 			return err
 		}
-		if len(cronjobs.Items) > 0 {
-			objectList.CronJobs = cronjobs
-		}
 	}
-	return err
+	return nil
+}
+
+// getLabelSelector returns the label selector of an included object, which
+// matches every object when it has none.
+func getLabelSelector(includedObject v1alpha1.IncludedObject) (labels.Selector, error) {
+	if includedObject.LabelSelector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(includedObject.LabelSelector)
 }
 
 func FetchIncludedObjects(ctx context.Context, Client client.Client, includedObjects []v1alpha1.IncludedObject, inclusive map[int][]bool) (ObjectList, error) {
 	var objectList = ObjectList{}
-	var err error
 
 	for index, includedObject := range includedObjects {
-		if inclusive[index+1][1] {
-			if inclusive[index+1][0] {
-				err = FetchAndFilter(ctx, Client, &objectList, "*", "*", includedObject.IncludeRef, includedObject.ExcludeRef, includedObject.Namespace)
-				if err != nil {
-					return ObjectList{}, err
-				}
+		apiVersion, kind := includedObject.ApiVersion, includedObject.Kind
+		if inclusive[index+1][0] {
+			if inclusive[index+1][1] {
+				kind = "*"
 			} else {
-				err = FetchAndFilter(ctx, Client, &objectList, includedObject.ApiVersion, includedObject.Kind, includedObject.IncludeRef, includedObject.ExcludeRef, includedObject.Namespace)
-				if err != nil {
-					return ObjectList{}, err
-				}
+				apiVersion = getSupportedObjectsApiVersionAndKind().GetAPIVersion(kind)
 			}
-		} else {
-			err = FetchAndFilter(ctx, Client, &objectList, includedObject.ApiVersion, includedObject.Kind, includedObject.IncludeRef, includedObject.ExcludeRef, includedObject.Namespace)
+		}
+		labelSelector, err := getLabelSelector(includedObject)
+		if err != nil {
+			return ObjectList{}, err
+		}
+		namespaces, err := resolveNamespaces(ctx, Client, includedObject)
+		if err != nil {
+			return ObjectList{}, err
+		}
+		for _, namespace := range namespaces {
+			resource := object.NewObject(Client, includedObject.IncludeRef, includedObject.ExcludeRef, namespace, labelSelector)
+			fetched := ObjectList{}
+			err = FetchAndFilter(ctx, &fetched, apiVersion, kind, resource)
 			if err != nil {
 				return ObjectList{}, err
 			}
+			if includedObject.SleepReplicas != nil {
+				objectList.setSleepReplicas(&fetched, *includedObject.SleepReplicas)
+			}
+			objectList.merge(&fetched)
 		}
 	}
-	return objectList, err
+	objectList.skipControlledObjects()
+	objectList.filterObjects(func(kind string, item metav1.Object) string {
+		return object.GetAnnotationsSkipReason(item)
+	})
+	return objectList, nil
 }
 
-func WriteChanges(ctx context.Context, Client client.Client, secret *corev1.Secret, list []object.ResourceInt, resourceKind string, failedObjects []string, failedObjectsSleepActions map[string][]string) error {
-	if len(list) != 0 {
-		err := SaveObjectsData(ctx, Client, secret, resourceKind, list)
-		if err != nil {
-			return err
-		}
+// WriteChanges saves the records of the resources of a kind, when there are
+// any or were any before.
+func WriteChanges(ctx context.Context, store StateStore, list []object.ResourceInt, resourceKind string, hadRecords bool) error {
+	if len(list) == 0 && !hadRecords {
+		return nil
 	}
-	if len(failedObjects) > 0 {
-		failedObjectsSleepActions[resourceKind] = failedObjects
-	}
-	return nil
+	return store.Save(ctx, resourceKind, list)
 }
 
 func checkOccurenceInSavedData(savedData []object.ResourceInt, resourceName, resourceNamespace string) (int, bool) {
@@ -352,220 +644,196 @@ func removeElementFromArray(arr []object.ResourceInt, index int) []object.Resour
 	return newArr
 }
 
-func putIncludedObjectsToSleep(ctx context.Context, Client client.Client, secret *corev1.Secret, includedObjects ObjectList) (map[string][]string, error) {
-	failedObjectsSleepActions := make(map[string][]string)
-	replicaResourceMap := object.NewReplicaResourceMap()
-	statusResourceMap := object.NewStatusResourceMap()
-	var err error
-	if includedObjects.Deployments != nil {
-		var savedResources []object.ResourceInt
-		var sleptResourcesToSave []object.ResourceInt
-		var failedObjects []string
-		dataExists := CheckIfSecretContainsDataOfKind(secret, "Deployment")
-		if dataExists {
-			savedResources, err = getSecretDatas(secret, "Deployment")
-			if err != nil {
-				return nil, err
-			}
-		}
-		for _, item := range includedObjects.Deployments.Items {
-			objectExists := false
-			index := 0
-			if len(savedResources) != 0 {
-				index, objectExists = checkOccurenceInSavedData(savedResources, item.Name, item.Namespace)
-			}
-
-			if !objectExists {
-				deployment := object.NewReplicaResource(item.Kind, item.Name, item.Namespace, *item.Spec.Replicas)
-				deployment.AddToList(replicaResourceMap)
-				failedObjects = deployment.PutToSleep(ctx, Client)
-			} else {
-				sleptResourcesToSave = append(sleptResourcesToSave, savedResources[index])
-				savedResources = removeElementFromArray(savedResources, index)
-			}
-		}
-
-		if len(savedResources) != 0 {
-			for _, resource := range savedResources {
-				err := resource.Wake(ctx, Client)
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
-
-		deploymentList := object.CastReplicaToGeneral(replicaResourceMap.Items["Deployment"])
-		deploymentList = append(deploymentList, sleptResourcesToSave...)
-
-		err = WriteChanges(ctx, Client, secret, deploymentList, "Deployment", failedObjects, failedObjectsSleepActions)
+// setResourceOwner records owner as the KronosApp holding a resource asleep,
+// along with a copy of its original state, an empty owner removing both.
+func setResourceOwner(ctx context.Context, Client client.Client, resource object.ResourceInt, owner string) error {
+	labels := map[string]*string{
+		object.OwnerLabel: nil,
+	}
+	annotations := map[string]*string{
+		object.OwnerAnnotation:            nil,
+		object.StateAnnotation:            nil,
+		object.OriginalReplicasAnnotation: nil,
+	}
+	if owner != "" {
+		labels[object.OwnerLabel] = ptr.To(object.GetOwnerLabel(owner))
+		state, err := json.Marshal(resource)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		annotations[object.OwnerAnnotation] = ptr.To(owner)
+		annotations[object.StateAnnotation] = ptr.To(string(state))
+		if replicaResource, ok := resource.(object.ReplicaResource); ok {
+			annotations[object.OriginalReplicasAnnotation] = ptr.To(strconv.Itoa(int(replicaResource.ResourceReplicas)))
 		}
 	}
+	apiVersion := getSupportedObjectsApiVersionAndKind().GetAPIVersion(resource.GetKind())
+	return object.SetMetadata(ctx, Client, apiVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName(), labels, annotations)
+}
 
-	if includedObjects.StatefulSets != nil {
-		savedResources := []object.ResourceInt{}
-		sleptResourcesToSave := []object.ResourceInt{}
-		var failedObjects []string
-		dataExists := CheckIfSecretContainsDataOfKind(secret, "StatefulSet")
-		if dataExists {
-			savedResources, err = getSecretDatas(secret, "StatefulSet")
-			if err != nil {
-				return nil, err
-			}
-		}
+// wakeResource restores the recorded state of a resource and releases it.
+func wakeResource(ctx context.Context, Client client.Client, resource object.ResourceInt) error {
+	err := resource.Wake(ctx, Client)
+	if err != nil {
+		return err
+	}
+	return setResourceOwner(ctx, Client, resource, "")
+}
 
-		for _, item := range includedObjects.StatefulSets.Items {
-			objectExists := false
-			index := 0
-			if len(savedResources) != 0 {
-				index, objectExists = checkOccurenceInSavedData(savedResources, item.Name, item.Namespace)
-			}
+// sleepOrAdopt records owner as the KronosApp holding a resource asleep and
+// puts it to sleep.
+func sleepOrAdopt(ctx context.Context, Client client.Client, resource object.ResourceInt, owner string) error {
+	err := setResourceOwner(ctx, Client, resource, owner)
+	if err != nil {
+		return err
+	}
+	return resource.PutToSleep(ctx, Client)
+}
 
-			if !objectExists {
-				statefulset := object.NewReplicaResource(item.Kind, item.Name, item.Namespace, *item.Spec.Replicas)
-				statefulset.AddToList(replicaResourceMap)
-				failedObjects = statefulset.PutToSleep(ctx, Client)
-			} else {
-				sleptResourcesToSave = append(sleptResourcesToSave, savedResources[index])
-				savedResources = removeElementFromArray(savedResources, index)
-			}
-		}
+func sleepResourcesOfKind(ctx context.Context, Client client.Client, store StateStore, kind string, resources []object.ResourceInt, owner, driftPolicy string, t *transition) error {
+	var resourcesToSave []object.ResourceInt
+	savedResources, err := store.Load(ctx, kind)
+	if err != nil {
+		return err
+	}
+	hadRecords := len(savedResources) != 0
+	for _, resource := range resources {
+		objectExists := false
+		index := 0
 		if len(savedResources) != 0 {
-			for _, resource := range savedResources {
-				err := resource.Wake(ctx, Client)
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
-
-		statefulsetList := object.CastReplicaToGeneral(replicaResourceMap.Items["StatefulSet"])
-		statefulsetList = append(statefulsetList, sleptResourcesToSave...)
-
-		err = WriteChanges(ctx, Client, secret, statefulsetList, "StatefulSet", failedObjects, failedObjectsSleepActions)
-		if err != nil {
-			return nil, err
+			index, objectExists = checkOccurenceInSavedData(savedResources, resource.GetName(), resource.GetNamespace())
 		}
-	}
 
-	if includedObjects.CronJobs != nil {
-		savedResources := []object.ResourceInt{}
-		sleptResourcesToSave := []object.ResourceInt{}
-		var failedObjects []string
-		dataExists := CheckIfSecretContainsDataOfKind(secret, "CronJob")
-		if dataExists {
-			savedResources, err = getSecretDatas(secret, "CronJob")
+		if !objectExists {
+			resourcesToSave = append(resourcesToSave, resource)
+			err := sleepOrAdopt(ctx, Client, resource, owner)
 			if err != nil {
-				return nil, err
-			}
-		}
-		for _, item := range includedObjects.CronJobs.Items {
-			objectExists := false
-			index := 0
-			if len(savedResources) != 0 {
-				index, objectExists = checkOccurenceInSavedData(savedResources, item.Name, item.Namespace)
-			}
-
-			if !objectExists {
-				cronjob := object.NewStatusResource(item.Kind, item.Name, item.Namespace, *item.Spec.Suspend)
-				cronjob.AddToList(statusResourceMap)
-				failedObjects = cronjob.PutToSleep(ctx, Client)
+				t.record(resource, v1alpha1.ResourceStateAwake, v1alpha1.ResourceActionSleep, err)
 			} else {
-				sleptResourcesToSave = append(sleptResourcesToSave, savedResources[index])
-				savedResources = removeElementFromArray(savedResources, index)
-			}
-		}
-		if len(savedResources) != 0 {
-			for _, resource := range savedResources {
-				err := resource.Wake(ctx, Client)
-				if err != nil {
-					return nil, err
-				}
+				t.record(resource, v1alpha1.ResourceStateAsleep, v1alpha1.ResourceActionSleep, nil)
 			}
+			continue
 		}
 
-		cronjobList := object.CastStatusToGeneral(statusResourceMap.Items["CronJob"])
-		cronjobList = append(cronjobList, sleptResourcesToSave...)
-
-		err = WriteChanges(ctx, Client, secret, cronjobList, "CronJob", failedObjects, failedObjectsSleepActions)
-		if err != nil {
-			return nil, err
+		savedResource := savedResources[index]
+		savedResources = removeElementFromArray(savedResources, index)
+		if resource.IsAsleep() {
+			resourcesToSave = append(resourcesToSave, savedResource)
+			t.record(savedResource, v1alpha1.ResourceStateAsleep, "", nil)
+			continue
 		}
-	}
-	if includedObjects.ReplicaSets != nil {
-		var savedResources []object.ResourceInt
-		var sleptResourcesToSave []object.ResourceInt
-		var failedObjects []string
-		dataExists := CheckIfSecretContainsDataOfKind(secret, "ReplicaSet")
-		if dataExists {
-			savedResources, err = getSecretDatas(secret, "ReplicaSet")
+		if t.failedPreviously(resource) {
+			// The resource could not be put to sleep, which is retried once
+			// its backoff expires.
+			resourcesToSave = append(resourcesToSave, savedResource)
+			if t.isBackingOff(resource) {
+				continue
+			}
+			err := sleepOrAdopt(ctx, Client, resource, owner)
 			if err != nil {
-				return nil, err
+				t.record(savedResource, v1alpha1.ResourceStateAwake, v1alpha1.ResourceActionSleep, err)
+			} else {
+				t.record(savedResource, v1alpha1.ResourceStateAsleep, v1alpha1.ResourceActionSleep, nil)
 			}
+			continue
 		}
-		for _, item := range includedObjects.ReplicaSets.Items {
-			objectExists := false
-			index := 0
-			if len(savedResources) != 0 {
-				index, objectExists = checkOccurenceInSavedData(savedResources, item.Name, item.Namespace)
+		// The resource was changed by anything but the controller since it
+		// was put to sleep.
+		t.recordDrift(resource)
+		switch driftPolicy {
+		case v1alpha1.DriftPolicyReport:
+			resourcesToSave = append(resourcesToSave, savedResource)
+			t.record(savedResource, v1alpha1.ResourceStateAwake, v1alpha1.ResourceActionReport, nil)
+		case v1alpha1.DriftPolicyAdopt:
+			resourcesToSave = append(resourcesToSave, resource)
+			err := sleepOrAdopt(ctx, Client, resource, owner)
+			if err != nil {
+				t.record(resource, v1alpha1.ResourceStateAwake, v1alpha1.ResourceActionAdopt, err)
+			} else {
+				t.record(resource, v1alpha1.ResourceStateAsleep, v1alpha1.ResourceActionAdopt, nil)
 			}
-
-			if !objectExists {
-				replicaset := object.NewReplicaResource(item.Kind, item.Name, item.Namespace, *item.Spec.Replicas)
-				replicaset.AddToList(replicaResourceMap)
-				failedObjects = replicaset.PutToSleep(ctx, Client)
+		default:
+			resourcesToSave = append(resourcesToSave, savedResource)
+			err := resource.PutToSleep(ctx, Client)
+			if err != nil {
+				t.record(savedResource, v1alpha1.ResourceStateAwake, v1alpha1.ResourceActionResleep, err)
 			} else {
-				sleptResourcesToSave = append(sleptResourcesToSave, savedResources[index])
-				savedResources = removeElementFromArray(savedResources, index)
+				t.record(savedResource, v1alpha1.ResourceStateAsleep, v1alpha1.ResourceActionResleep, nil)
 			}
 		}
+	}
 
-		if len(savedResources) != 0 {
-			for _, resource := range savedResources {
-				err := resource.Wake(ctx, Client)
-				if err != nil {
-					return nil, err
-				}
-			}
+	// Resources recorded previously that no longer match the included objects
+	// are given back their original state, unless they were deleted meanwhile.
+	// Those that cannot be woken up stay recorded until they are.
+	for _, resource := range savedResources {
+		err := wakeResource(ctx, Client, resource)
+		if err != nil && !apierrors.IsNotFound(err) {
+			resourcesToSave = append(resourcesToSave, resource)
+			t.record(resource, v1alpha1.ResourceStateAsleep, v1alpha1.ResourceActionWake, err)
+			continue
 		}
+		t.record(resource, v1alpha1.ResourceStateAwake, v1alpha1.ResourceActionWake, nil)
+	}
 
-		replicasetList := object.CastReplicaToGeneral(replicaResourceMap.Items["ReplicaSet"])
-		replicasetList = append(replicasetList, sleptResourcesToSave...)
+	return WriteChanges(ctx, store, resourcesToSave, kind, hadRecords)
+}
 
-		err = WriteChanges(ctx, Client, secret, replicasetList, "ReplicaSet", failedObjects, failedObjectsSleepActions)
+// putIncludedObjectsToSleep puts the included objects to sleep, recording
+// their original state in the store. It returns what happened to each of
+// them, previous holding the objects reported by the previous
+// reconciliation, whose failed actions are retried once their backoff
+// expires. Every kind is gone through, so that the objects recorded but no
+// longer included, such as those excluded or skipped since, are woken up even
+// when none of their kind is left.
+func putIncludedObjectsToSleep(ctx context.Context, Client client.Client, store StateStore, includedObjects ObjectList, previous []v1alpha1.ManagedResource) (*transition, error) {
+	t := newTransition(previous, time.Now())
+	for _, kind := range getAllKinds() {
+		var resources []object.ResourceInt
+		if includedObjects.ContainsKind(kind) {
+			resources = includedObjects.GetResources(kind)
+		}
+		err := sleepResourcesOfKind(ctx, Client, store, kind, resources, includedObjects.Owner, includedObjects.DriftPolicy, t)
 		if err != nil {
-			return nil, err
+			return t, err
 		}
 	}
-	return failedObjectsSleepActions, nil
+	return t, nil
 }
 
-func getDataFromSecret(secret *corev1.Secret) ([]object.ResourceInt, error) {
-	var resourceList []object.ResourceInt
-	var allKinds = getAllKinds()
-
-	for _, kind := range allKinds {
-		var newResourceList, err = getSecretDatas(secret, kind)
-		if err != nil {
-			return nil, err
-		}
-		resourceList = append(resourceList, newResourceList...)
+// WakeUpResources gives back their original state to the resources recorded
+// in the store, returning an error listing those that could not be woken up.
+func WakeUpResources(ctx context.Context, Client client.Client, store StateStore) error {
+	t := newTransition(nil, time.Now())
+	_, err := wakeUpResources(ctx, Client, store, t)
+	if err != nil {
+		return err
 	}
-	return resourceList, nil
+	return t.err()
 }
 
-func WakeUpResources(ctx context.Context, Client client.Client, secret *corev1.Secret) error {
-	resourceList, err := getDataFromSecret(secret)
+// wakeUpResources gives back their original state to the resources recorded
+// in the store, recording the outcome in t, and returns those that could not
+// be woken up or are backing off.
+func wakeUpResources(ctx context.Context, Client client.Client, store StateStore, t *transition) ([]object.ResourceInt, error) {
+	resourceList, err := loadAllRecords(ctx, store)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var asleep []object.ResourceInt
 	for _, resource := range resourceList {
-		err = resource.Wake(ctx, Client)
-		if err != nil {
-			return err
+		if t.isBackingOff(resource) {
+			asleep = append(asleep, resource)
+			continue
+		}
+		err = wakeResource(ctx, Client, resource)
+		if err != nil && !apierrors.IsNotFound(err) {
+			asleep = append(asleep, resource)
+			t.record(resource, v1alpha1.ResourceStateAsleep, v1alpha1.ResourceActionWake, err)
+			continue
 		}
+		t.record(resource, v1alpha1.ResourceStateAwake, v1alpha1.ResourceActionWake, nil)
 	}
-	return nil
+	return asleep, nil
 }
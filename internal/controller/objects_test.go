@@ -0,0 +1,290 @@
+package kronosapp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newTestClient returns a fake client holding the given objects. The fake
+// client not serving the scale subresource, the patches sent to it are
+// applied to the workload itself, their replicas being at the same path, with
+// their options so that dry runs are kept.
+func newTestClient(t *testing.T, objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(&v1alpha1.KronosApp{}, &v1alpha1.ClusterKronosApp{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				if subResourceName == "scale" {
+					patchOptions := &client.SubResourcePatchOptions{}
+					patchOptions.ApplyOptions(opts)
+					return c.Patch(ctx, obj, patch, &patchOptions.PatchOptions)
+				}
+				return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+			},
+		}).
+		Build()
+}
+
+func TestFetchIncludedObjectsLabelSelector(t *testing.T) {
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"tier": "web"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(2))},
+	}
+	tests := []struct {
+		name          string
+		labelSelector *metav1.LabelSelector
+		expected      int
+	}{
+		{name: "no selector", expected: 1},
+		{name: "empty selector", labelSelector: &metav1.LabelSelector{}, expected: 1},
+		{name: "matching selector", labelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}}, expected: 1},
+		{name: "other selector", labelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "db"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			includedObjects := []v1alpha1.IncludedObject{{
+				ApiVersion:    "apps/v1",
+				Kind:          "Deployment",
+				Namespace:     "default",
+				LabelSelector: test.labelSelector,
+			}}
+			inclusive, err := ValidateIncludedObjects(includedObjects)
+			if err != nil {
+				t.Fatal(err)
+			}
+			objectList, err := FetchIncludedObjects(context.Background(), newTestClient(t, web.DeepCopy()), includedObjects, inclusive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fetched := 0
+			if objectList.Deployments != nil {
+				fetched = len(objectList.Deployments.Items)
+			}
+			if fetched != test.expected {
+				t.Errorf("expected %d Deployments, got %d", test.expected, fetched)
+			}
+		})
+	}
+}
+
+func TestGetResourcesCronJobSuspend(t *testing.T) {
+	if object.IsSuspended(nil) {
+		t.Fatal("expected an unset suspend field not to be suspended")
+	}
+	tests := []struct {
+		name     string
+		suspend  *bool
+		expected bool
+	}{
+		{name: "unset", suspend: nil, expected: false},
+		{name: "false", suspend: ptr.To(false), expected: false},
+		{name: "true", suspend: ptr.To(true), expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			includedObjects := ObjectList{CronJobs: &batchv1.CronJobList{Items: []batchv1.CronJob{{
+				ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+				Spec:       batchv1.CronJobSpec{Suspend: test.suspend},
+			}}}}
+			resources := includedObjects.GetResources("CronJob")
+			if len(resources) != 1 {
+				t.Fatalf("expected 1 resource, got %d", len(resources))
+			}
+			resource, ok := resources[0].(object.StatusResource)
+			if !ok {
+				t.Fatalf("expected a StatusResource, got %T", resources[0])
+			}
+			if resource.ResourceStatus == nil || *resource.ResourceStatus != test.expected {
+				t.Errorf("expected the prior suspend value %v to be recorded, got %v", test.expected, resource.ResourceStatus)
+			}
+			data, err := json.Marshal(resource)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var record map[string]interface{}
+			if err := json.Unmarshal(data, &record); err != nil {
+				t.Fatal(err)
+			}
+			if record["suspended"] != test.expected {
+				t.Errorf("expected %v to be stored, got %v", test.expected, record["suspended"])
+			}
+			managedResource := newManagedResource(resource)
+			if managedResource.OriginalSuspend == nil || *managedResource.OriginalSuspend != test.expected {
+				t.Errorf("expected %v to be reported, got %v", test.expected, managedResource.OriginalSuspend)
+			}
+		})
+	}
+}
+
+func TestPutIncludedObjectsToSleepWakesObjectsNoLongerIncluded(t *testing.T) {
+	ctx := context.Background()
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: map[string]string{object.ExcludeAnnotation: "true"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(0))},
+	}
+	Client := newTestClient(t, web)
+	store := newDryRunStore(nil)
+	recorded := newTestReplicaResource("web")
+	recorded.ResourceReplicas = 3
+	err := store.Save(ctx, "Deployment", []object.ResourceInt{recorded})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The exclude annotation left out the last Deployment included.
+	includedObjects := ObjectList{Deployments: &appsv1.DeploymentList{Items: []appsv1.Deployment{*web}}}
+	includedObjects.filterObjects(func(kind string, item metav1.Object) string {
+		return object.GetAnnotationsSkipReason(item)
+	})
+	if includedObjects.ContainsKind("Deployment") {
+		t.Fatal("expected no Deployment to be left included")
+	}
+	tr, err := putIncludedObjectsToSleep(ctx, Client, store, includedObjects, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.err(); err != nil {
+		t.Fatal(err)
+	}
+	err = Client.Get(ctx, client.ObjectKeyFromObject(web), web)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *web.Spec.Replicas != 3 {
+		t.Errorf("expected the excluded Deployment to be woken up to 3 replicas, got %d", *web.Spec.Replicas)
+	}
+	resources, err := store.Load(ctx, "Deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 0 {
+		t.Errorf("expected the record to be removed, got %+v", resources)
+	}
+}
+
+func TestSkipControlledObjects(t *testing.T) {
+	newReplicaSet := func(name string, controller *appsv1.Deployment) appsv1.ReplicaSet {
+		replicaSet := appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)}}
+		replicaSet.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(controller, appsv1.SchemeGroupVersion.WithKind("Deployment"))}
+		return replicaSet
+	}
+	web := newTestDeployment("web", "")
+	web.UID = "web"
+	api := newTestDeployment("api", "")
+	api.UID = "api"
+
+	includedObjects := ObjectList{
+		Deployments: &appsv1.DeploymentList{Items: []appsv1.Deployment{web}},
+		ReplicaSets: &appsv1.ReplicaSetList{Items: []appsv1.ReplicaSet{
+			newReplicaSet("web-1", &web),
+			newReplicaSet("web-2", &web),
+			newReplicaSet("api-1", &api),
+			{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default", UID: "standalone"}},
+		}},
+	}
+	includedObjects.skipControlledObjects()
+
+	if includedObjects.ReplicaSets == nil || len(includedObjects.ReplicaSets.Items) != 1 || includedObjects.ReplicaSets.Items[0].Name != "standalone" {
+		t.Errorf("expected only the standalone ReplicaSet to be kept, got %+v", includedObjects.ReplicaSets)
+	}
+	if len(includedObjects.Skipped) != 1 || includedObjects.Skipped[0].Name != "api-1" || includedObjects.Skipped[0].Reason != "controlled by Deployment/api" {
+		t.Errorf("expected only the ReplicaSet of the Deployment not included to be reported, got %+v", includedObjects.Skipped)
+	}
+}
+
+func TestSetSleepReplicas(t *testing.T) {
+	includedObjects := ObjectList{}
+	first := ObjectList{Deployments: &appsv1.DeploymentList{Items: []appsv1.Deployment{newTestDeployment("web", ""), newTestDeployment("api", "")}}}
+	second := ObjectList{Deployments: &appsv1.DeploymentList{Items: []appsv1.Deployment{newTestDeployment("api", ""), newTestDeployment("worker", "")}}}
+	includedObjects.setSleepReplicas(&first, 1)
+	includedObjects.setSleepReplicas(&second, 2)
+
+	expected := map[string]int32{
+		"Deployment/default/web":    1,
+		"Deployment/default/api":    1,
+		"Deployment/default/worker": 2,
+	}
+	if len(includedObjects.SleepReplicas) != len(expected) {
+		t.Fatalf("expected %d sleep replicas, got %v", len(expected), includedObjects.SleepReplicas)
+	}
+	for key, sleepReplicas := range expected {
+		if includedObjects.SleepReplicas[key] != sleepReplicas {
+			t.Errorf("expected %s to keep %d replicas, got %d", key, sleepReplicas, includedObjects.SleepReplicas[key])
+		}
+	}
+}
+
+func TestSleepReplicasFloor(t *testing.T) {
+	tests := []struct {
+		name       string
+		replicas   int32
+		floor      *int32
+		annotation string
+		expected   int32
+	}{
+		{name: "no floor", replicas: 3, expected: 0},
+		{name: "included object floor", replicas: 3, floor: ptr.To(int32(1)), expected: 1},
+		{name: "below the floor", replicas: 1, floor: ptr.To(int32(2)), expected: 1},
+		{name: "annotation overrides the floor", replicas: 3, floor: ptr.To(int32(1)), annotation: "2", expected: 2},
+		{name: "annotation set to zero", replicas: 3, floor: ptr.To(int32(1)), annotation: "0", expected: 0},
+		{name: "annotation without floor", replicas: 3, annotation: "1", expected: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			web := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(test.replicas)},
+			}
+			if test.annotation != "" {
+				web.Annotations = map[string]string{object.SleepReplicasAnnotation: test.annotation}
+			}
+			Client := newTestClient(t, web)
+			includedObjects := ObjectList{}
+			fetched := ObjectList{Deployments: &appsv1.DeploymentList{Items: []appsv1.Deployment{*web}}}
+			if test.floor != nil {
+				includedObjects.setSleepReplicas(&fetched, *test.floor)
+			}
+			includedObjects.merge(&fetched)
+
+			resources := includedObjects.GetResources("Deployment")
+			if len(resources) != 1 {
+				t.Fatalf("expected one resource, got %+v", resources)
+			}
+			err := resources[0].PutToSleep(ctx, Client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Client.Get(ctx, client.ObjectKeyFromObject(web), web)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *web.Spec.Replicas != test.expected {
+				t.Errorf("expected %d replicas while asleep, got %d", test.expected, *web.Spec.Replicas)
+			}
+		})
+	}
+}
@@ -230,15 +230,18 @@ func IsItHoliday(schedule SleepSchedule) (bool, time.Duration) {
 	return isHoliday, holidayDuration
 }
 
-func IsTimeToSleep(schedule SleepSchedule, kronosapp *v1alpha1.KronosApp) (bool, bool, time.Duration, error) {
+func IsTimeToSleep(schedule SleepSchedule, kronosapp v1alpha1.KronosAppObject) (bool, bool, time.Duration, error) {
+	if kronosapp.GetSpec().IsWakeOverrideActive(schedule.now) {
+		return false, false, 0, nil
+	}
 	ok, holidayDuration := IsItHoliday(schedule)
 	if ok {
 		return true, true, holidayDuration, nil
 	}
-	if kronosapp.Spec.ForceSleep {
+	if kronosapp.GetSpec().ForceSleep {
 		return false, true, 0, nil
 	}
-	if kronosapp.Spec.ForceWake {
+	if kronosapp.GetSpec().ForceWake {
 		return false, false, 0, nil
 	}
 	// Check if today is one of the weekdays specified
@@ -271,6 +274,19 @@ func getRequeueTime(schedule SleepSchedule) time.Duration {
 	return nextRequeueDiff
 }
 
+// boundRequeueTime shortens the requeue time so that the KronosApp is
+// reconciled again as soon as its wake override expires.
+func boundRequeueTime(schedule SleepSchedule, kronosapp v1alpha1.KronosAppObject, requeueTime time.Duration) time.Duration {
+	if !kronosapp.GetSpec().IsWakeOverrideActive(schedule.now) {
+		return requeueTime
+	}
+	overrideDuration := kronosapp.GetSpec().WakeUntil.Sub(schedule.now)
+	if overrideDuration < requeueTime {
+		return overrideDuration
+	}
+	return requeueTime
+}
+
 func formatDuration(d time.Duration) string {
 	hours := d / time.Hour
 	minutes := (d % time.Hour) / time.Minute
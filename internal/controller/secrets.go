@@ -2,34 +2,40 @@ package kronosapp
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/KronosOrg/kronos-core/api/v1alpha1"
 	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;create;update;delete;watch
+const (
+	// ChecksumAnnotation holds the checksum of the data of the Secret or
+	// ConfigMap of a KronosApp, revealing changes made by anything but the
+	// controller.
+	ChecksumAnnotation = "kronos.wecraft.tn/checksum"
+	// KronosAppLabel and KronosAppKindLabel designate the KronosApp a
+	// Secret or ConfigMap belongs to.
+	KronosAppLabel     = "kronos.wecraft.tn/kronosapp"
+	KronosAppKindLabel = "kronos.wecraft.tn/kind"
+)
 
-func (r *KronosAppReconciler) getSecret(ctx context.Context, name, namespace string) (*corev1.Secret, error) {
-	secret := &corev1.Secret{}
-	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret)
-	if err != nil {
-		return nil, err
-	}
-	return secret, nil
-}
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;create;update;delete;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;update;delete;watch
 
-func checkIfSecretWasCreatedPreviously(kronosApp *v1alpha1.KronosApp, name string) error {
-	if kronosApp.Status.CreatedSecrets == nil && len(kronosApp.Status.CreatedSecrets) == 0 {
+func checkIfSecretWasCreatedPreviously(kronosApp v1alpha1.KronosAppObject, name string) error {
+	if kronosApp.GetStatus().CreatedSecrets == nil && len(kronosApp.GetStatus().CreatedSecrets) == 0 {
 		return errors.New("there is no created secrets")
 	} else {
-		ok := IsInArray(kronosApp.Status.CreatedSecrets, name)
+		ok := IsInArray(kronosApp.GetStatus().CreatedSecrets, name)
 		if ok {
 			err := fmt.Errorf("WARNING: %s was not found but recorded as created. Possible tamper or missing data", name)
 			return err
@@ -43,79 +49,131 @@ func getSecretName(name string) string {
 	return fmt.Sprintf("kronosapp-%s", name)
 }
 
-func (r *KronosAppReconciler) createSecret(ctx context.Context, name, namespace string) error {
-	secret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: namespace,
-			Name:      name,
-		},
+func getClusterSecretName(name string) string {
+	return fmt.Sprintf("clusterkronosapp-%s", name)
+}
+
+func (r *KronosAppReconciler) registerSecret(ctx context.Context, name string, kronosApp v1alpha1.KronosAppObject) error {
+	if IsInArray(kronosApp.GetStatus().CreatedSecrets, name) {
+		return nil
 	}
-	err := r.Create(ctx, secret)
+	kronosappCopy := kronosApp.DeepCopyObject().(v1alpha1.KronosAppObject)
+	kronosappCopy.GetStatus().CreatedSecrets = append(kronosappCopy.GetStatus().CreatedSecrets, name)
+	err := r.Status().Update(ctx, kronosappCopy)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *KronosAppReconciler) registerSecret(ctx context.Context, name string, kronosApp *v1alpha1.KronosApp) error {
-	kronosappCopy := kronosApp.DeepCopy()
-	kronosappCopy.Status.CreatedSecrets = append(kronosappCopy.Status.CreatedSecrets, name)
-	err := r.Update(ctx, kronosappCopy)
-	if err != nil {
-		return err
+// getDocumentData returns the data of the Secret or ConfigMap holding the
+// state of a KronosApp.
+func getDocumentData(document client.Object) *map[string][]byte {
+	switch document := document.(type) {
+	case *corev1.Secret:
+		return &document.Data
+	case *corev1.ConfigMap:
+		return &document.BinaryData
 	}
-	return nil
+	return new(map[string][]byte)
 }
 
-func SaveObjectsData(ctx context.Context, Client client.Client, secret *corev1.Secret, kind string, resourceList []object.ResourceInt) error {
-	if secret.Data == nil {
-		secret.Data = make(map[string][]byte)
+func getChecksum(data map[string][]byte) string {
+	var keys []string
+	for key := range data {
+		keys = append(keys, key)
 	}
-	// Create or update the JSON object for the given kind
-	dataJSON, err := json.Marshal(resourceList)
-	if err != nil {
-		return err
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(data[key])
+		hash.Write([]byte{0})
 	}
-	secret.Data[kind] = dataJSON
-	secret.ObjectMeta.SetResourceVersion("") // Ensure update
-	if err := Client.Update(ctx, secret); err != nil {
-		return err
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func setChecksum(document client.Object) {
+	annotations := document.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
 	}
-	return nil
+	annotations[ChecksumAnnotation] = getChecksum(*getDocumentData(document))
+	document.SetAnnotations(annotations)
 }
 
-func CheckIfSecretContainsData(secret *corev1.Secret) error {
-	if secret.Data == nil {
-		err := fmt.Errorf("secret %s does not contain any data", secret.Name)
-		return err
+// checkChecksum returns an error when the data of a Secret or ConfigMap does
+// not match its checksum. Documents written before checksums were introduced
+// have none and are trusted.
+func checkChecksum(document client.Object) error {
+	checksum, ok := document.GetAnnotations()[ChecksumAnnotation]
+	if !ok || checksum == getChecksum(*getDocumentData(document)) {
+		return nil
 	}
-	return nil
+	return fmt.Errorf("data of %s does not match its checksum, it may have been tampered with", document.GetName())
 }
 
-func CheckIfSecretContainsDataOfKind(secret *corev1.Secret, kind string) bool {
-	return secret.Data[kind] != nil
+// stateDocumentPredicate lets through the deletion of the Secret or ConfigMap
+// holding the state of a KronosApp and the changes made to its data by
+// anything but the controller.
+func stateDocumentPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return checkChecksum(e.ObjectNew) != nil
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
 }
 
-func getSecretDatas(secret *corev1.Secret, kind string) ([]object.ResourceInt, error) {
+// decodeRecords decodes the records of the resources of a kind.
+func decodeRecords(kind string, data []byte) ([]object.ResourceInt, error) {
 	var resourceList []object.ResourceInt
 	var err error
+	if data == nil {
+		return nil, nil
+	}
 	switch kind {
 	case "Deployment", "StatefulSet", "ReplicaSet":
 		{
 			var jsonData = []object.ReplicaResource{}
-			if secret.Data[kind] != nil {
-				err = json.Unmarshal(secret.Data[kind], &jsonData)
-				resourceList = object.CastReplicaToGeneral(jsonData)
-			}
+			err = json.Unmarshal(data, &jsonData)
+			resourceList = object.CastReplicaToGeneral(jsonData)
 		}
-	case "CronJob":
+	case "CronJob", "Kustomization", "HelmRelease":
 		{
 			var jsonData = []object.StatusResource{}
-			if secret.Data[kind] != nil {
-				err = json.Unmarshal(secret.Data[kind], &jsonData)
-				resourceList = object.CastStatusToGeneral(jsonData)
-			}
+			err = json.Unmarshal(data, &jsonData)
+			resourceList = object.CastStatusToGeneral(jsonData)
 		}
+	case "Application":
+		{
+			var jsonData = []object.SyncPolicyResource{}
+			err = json.Unmarshal(data, &jsonData)
+			resourceList = object.CastSyncPolicyToGeneral(jsonData)
+		}
+	case "ScaledObject", "ScaledJob":
+		{
+			var jsonData = []object.AnnotationResource{}
+			err = json.Unmarshal(data, &jsonData)
+			resourceList = object.CastAnnotationToGeneral(jsonData)
+		}
+	case "Ingress":
+		{
+			var jsonData = []object.IngressResource{}
+			err = json.Unmarshal(data, &jsonData)
+			resourceList = object.CastIngressToGeneral(jsonData)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %s in state records", kind)
 	}
 
 	if err != nil {
@@ -123,12 +181,3 @@ func getSecretDatas(secret *corev1.Secret, kind string) ([]object.ResourceInt, e
 	}
 	return resourceList, nil
 }
-
-func purgeSecretData(ctx context.Context, Client client.Client, secret *corev1.Secret) error {
-	secret.Data = nil
-	err := Client.Update(ctx, secret)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,64 @@
+package kronosapp
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetChecksum(t *testing.T) {
+	data := map[string][]byte{"Deployment": []byte("[]"), "CronJob": []byte("[1]")}
+	if getChecksum(data) != getChecksum(map[string][]byte{"CronJob": []byte("[1]"), "Deployment": []byte("[]")}) {
+		t.Error("expected the checksum not to depend on the order of the keys")
+	}
+	if getChecksum(data) == getChecksum(map[string][]byte{"Deployment": []byte("[]"), "CronJob": []byte("[2]")}) {
+		t.Error("expected the checksum to change with the values")
+	}
+	if getChecksum(map[string][]byte{"ab": []byte("c")}) == getChecksum(map[string][]byte{"a": []byte("bc")}) {
+		t.Error("expected keys and values to be delimited")
+	}
+	if getChecksum(nil) != getChecksum(map[string][]byte{}) {
+		t.Error("expected nil and empty data to have the same checksum")
+	}
+}
+
+func TestCheckChecksum(t *testing.T) {
+	newSecret := func() *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "kronosapp-test"},
+			Data:       map[string][]byte{"Deployment": []byte("[]")},
+		}
+	}
+	newConfigMap := func() *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "kronosapp-test"},
+			BinaryData: map[string][]byte{"Deployment": []byte("[]")},
+		}
+	}
+
+	legacy := newSecret()
+	if err := checkChecksum(legacy); err != nil {
+		t.Errorf("expected a document without checksum to be trusted, got %v", err)
+	}
+
+	secret := newSecret()
+	setChecksum(secret)
+	if err := checkChecksum(secret); err != nil {
+		t.Errorf("expected a stamped Secret to match, got %v", err)
+	}
+	secret.Data["Deployment"] = []byte(`[{"name":"web"}]`)
+	if err := checkChecksum(secret); err == nil {
+		t.Error("expected a tampered Secret to be reported")
+	}
+
+	configMap := newConfigMap()
+	setChecksum(configMap)
+	if err := checkChecksum(configMap); err != nil {
+		t.Errorf("expected a stamped ConfigMap to match, got %v", err)
+	}
+	delete(configMap.BinaryData, "Deployment")
+	if err := checkChecksum(configMap); err == nil {
+		t.Error("expected a tampered ConfigMap to be reported")
+	}
+}
@@ -0,0 +1,486 @@
+package kronosapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// The state stores, selected with the --state-store flag of the operator.
+const (
+	StateStoreSecret      = "secret"
+	StateStoreConfigMap   = "configmap"
+	StateStoreAnnotations = "annotations"
+)
+
+// StateStore keeps the original state of the resources put to sleep by a
+// KronosApp, so that it can be restored on wake.
+type StateStore interface {
+	// Load returns the records of the resources of a kind.
+	Load(ctx context.Context, kind string) ([]object.ResourceInt, error)
+	// Save replaces the records of the resources of a kind.
+	Save(ctx context.Context, kind string, resources []object.ResourceInt) error
+	// Purge removes every record.
+	Purge(ctx context.Context) error
+	// Delete removes the store along with its records.
+	Delete(ctx context.Context) error
+	// Verify returns an error when the records were changed by anything but
+	// the controller.
+	Verify() error
+}
+
+// StateVersion is the version of the state documents written by the
+// controller. Version 1 documents are the bare JSON arrays of records written
+// by earlier releases.
+const StateVersion = 2
+
+// stateDocument holds the records of the resources of a kind, along with what
+// is needed to tell how and when they were captured.
+type stateDocument struct {
+	Version    int             `json:"version"`
+	Kind       string          `json:"kind"`
+	CapturedAt metav1.Time     `json:"capturedAt"`
+	KronosApp  string          `json:"kronosApp,omitempty"`
+	Generation int64           `json:"generation,omitempty"`
+	Resources  json.RawMessage `json:"resources"`
+}
+
+func encodeStateDocument(kind string, resources []object.ResourceInt, kronosApp string, generation int64) ([]byte, error) {
+	resourcesJSON, err := json.Marshal(resources)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(stateDocument{
+		Version:    StateVersion,
+		Kind:       kind,
+		CapturedAt: metav1.Now(),
+		KronosApp:  kronosApp,
+		Generation: generation,
+		Resources:  resourcesJSON,
+	})
+}
+
+// decodeStateDocument decodes the records of the resources of a kind from a
+// state document of any known version.
+func decodeStateDocument(kind string, data []byte) ([]object.ResourceInt, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if data[0] == '[' {
+		return decodeRecords(kind, data)
+	}
+	var document stateDocument
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, fmt.Errorf("decoding state of %s: %w", kind, err)
+	}
+	switch document.Version {
+	case StateVersion:
+		if document.Kind != kind {
+			return nil, fmt.Errorf("state recorded for %s holds %s records", kind, document.Kind)
+		}
+		return decodeRecords(kind, document.Resources)
+	default:
+		return nil, fmt.Errorf("unsupported version %d of the state of %s, the controller supports up to version %d", document.Version, kind, StateVersion)
+	}
+}
+
+// documentStore keeps the records in a Secret or a ConfigMap, as a state
+// document per kind.
+type documentStore struct {
+	Client   client.Client
+	document client.Object
+	// kronosApp and generation designate the KronosApp capturing the state.
+	kronosApp  string
+	generation int64
+}
+
+func newDocumentStore(Client client.Client, document client.Object) *documentStore {
+	return &documentStore{
+		Client:   Client,
+		document: document,
+	}
+}
+
+func (s *documentStore) Load(ctx context.Context, kind string) ([]object.ResourceInt, error) {
+	return decodeStateDocument(kind, (*getDocumentData(s.document))[kind])
+}
+
+// update applies a change to the Secret or ConfigMap and writes it. When it
+// was modified meanwhile, the latest version is fetched and the change applied
+// to it again, unless the records it holds were tampered with.
+func (s *documentStore) update(ctx context.Context, change func(data *map[string][]byte)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		change(getDocumentData(s.document))
+		setChecksum(s.document)
+		err := s.Client.Update(ctx, s.document)
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+		*getDocumentData(s.document) = nil
+		getErr := s.Client.Get(ctx, client.ObjectKeyFromObject(s.document), s.document)
+		if getErr != nil {
+			return getErr
+		}
+		checkErr := checkChecksum(s.document)
+		if checkErr != nil {
+			return checkErr
+		}
+		return err
+	})
+}
+
+func (s *documentStore) Save(ctx context.Context, kind string, resources []object.ResourceInt) error {
+	dataJSON, err := encodeStateDocument(kind, resources, s.kronosApp, s.generation)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, func(data *map[string][]byte) {
+		if *data == nil {
+			*data = make(map[string][]byte)
+		}
+		(*data)[kind] = dataJSON
+	})
+}
+
+func (s *documentStore) Purge(ctx context.Context) error {
+	return s.update(ctx, func(data *map[string][]byte) {
+		*data = nil
+	})
+}
+
+func (s *documentStore) Delete(ctx context.Context) error {
+	return client.IgnoreNotFound(s.Client.Delete(ctx, s.document))
+}
+
+func (s *documentStore) Verify() error {
+	return checkChecksum(s.document)
+}
+
+// annotationStore keeps the record of each resource in its own annotations,
+// next to the owner annotation naming the KronosApp holding it asleep.
+type annotationStore struct {
+	Client client.Client
+	// Reader lists the objects carrying the owner label of the KronosApp.
+	Reader  client.Reader
+	owner   string
+	records map[string][]object.ResourceInt
+}
+
+func newAnnotationStore(Client client.Client, Reader client.Reader, owner string) *annotationStore {
+	return &annotationStore{
+		Client: Client,
+		Reader: Reader,
+		owner:  owner,
+	}
+}
+
+// load lists the objects of every supported kind owned by the KronosApp,
+// once per store, selecting them by their owner label.
+func (s *annotationStore) load(ctx context.Context) error {
+	if s.records != nil {
+		return nil
+	}
+	records := make(map[string][]object.ResourceInt)
+	for _, kind := range getAllKinds() {
+		list := &unstructured.UnstructuredList{}
+		list.SetAPIVersion(getSupportedObjectsApiVersionAndKind().GetAPIVersion(kind))
+		list.SetKind(kind + "List")
+		err := s.Reader.List(ctx, list, client.MatchingLabels{object.OwnerLabel: object.GetOwnerLabel(s.owner)})
+		if err != nil {
+			if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		var states []json.RawMessage
+		for index := range list.Items {
+			state := object.GetState(&list.Items[index])
+			if object.GetOwner(&list.Items[index]) == s.owner && json.Valid([]byte(state)) {
+				states = append(states, json.RawMessage(state))
+			}
+		}
+		if len(states) == 0 {
+			continue
+		}
+		data, err := json.Marshal(states)
+		if err != nil {
+			return err
+		}
+		records[kind], err = decodeRecords(kind, data)
+		if err != nil {
+			return err
+		}
+	}
+	s.records = records
+	return nil
+}
+
+func (s *annotationStore) Load(ctx context.Context, kind string) ([]object.ResourceInt, error) {
+	err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.records[kind], nil
+}
+
+func (s *annotationStore) Save(ctx context.Context, kind string, resources []object.ResourceInt) error {
+	err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		_, recorded := checkOccurenceInSavedData(s.records[kind], resource.GetName(), resource.GetNamespace())
+		if recorded {
+			continue
+		}
+		err = setResourceOwner(ctx, s.Client, resource, s.owner)
+		if err != nil {
+			return err
+		}
+	}
+	s.records[kind] = resources
+	return nil
+}
+
+func (s *annotationStore) Purge(ctx context.Context) error {
+	err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	for _, resources := range s.records {
+		for _, resource := range resources {
+			err = setResourceOwner(ctx, s.Client, resource, "")
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	s.records = make(map[string][]object.ResourceInt)
+	return nil
+}
+
+func (s *annotationStore) Delete(ctx context.Context) error {
+	return s.Purge(ctx)
+}
+
+func (s *annotationStore) Verify() error {
+	return nil
+}
+
+// isStateStoreEmpty reports whether a store holds no record.
+func isStateStoreEmpty(ctx context.Context, store StateStore) (bool, error) {
+	resourceList, err := loadAllRecords(ctx, store)
+	if err != nil {
+		return false, err
+	}
+	return len(resourceList) == 0, nil
+}
+
+// loadAllRecords returns the records of every kind, in wake order.
+func loadAllRecords(ctx context.Context, store StateStore) ([]object.ResourceInt, error) {
+	var resourceList []object.ResourceInt
+	for _, kind := range getWakeOrder() {
+		newResourceList, err := store.Load(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+		resourceList = append(resourceList, newResourceList...)
+	}
+	return resourceList, nil
+}
+
+// migrateState copies the records of the kinds the destination store has
+// none of.
+func migrateState(ctx context.Context, from, to StateStore) (int, error) {
+	migrated := 0
+	for _, kind := range getAllKinds() {
+		resources, err := from.Load(ctx, kind)
+		if err != nil {
+			return migrated, err
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		existing, err := to.Load(ctx, kind)
+		if err != nil {
+			return migrated, err
+		}
+		if len(existing) != 0 {
+			continue
+		}
+		err = to.Save(ctx, kind, resources)
+		if err != nil {
+			return migrated, err
+		}
+		migrated += len(resources)
+	}
+	return migrated, nil
+}
+
+func newStateDocument(stateStore, name, namespace string) client.Object {
+	if stateStore == StateStoreConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+}
+
+// getSelectedStateStore returns the state store selected by the operator,
+// Secrets when the reconciler was built without one.
+func (r *KronosAppReconciler) getSelectedStateStore() string {
+	if r.StateStore == "" {
+		return StateStoreSecret
+	}
+	return r.StateStore
+}
+
+// getAPIReader returns the reader bypassing the cache of the manager, the
+// client when the reconciler has none.
+func (r *KronosAppReconciler) getAPIReader() client.Reader {
+	if r.APIReader == nil {
+		return r.Client
+	}
+	return r.APIReader
+}
+
+// getOwnedStateDocument returns an object of the kind of the documents of the
+// selected state store, nil when the state is kept in annotations.
+func (r *KronosAppReconciler) getOwnedStateDocument() client.Object {
+	switch r.getSelectedStateStore() {
+	case StateStoreAnnotations:
+		return nil
+	case StateStoreConfigMap:
+		return &corev1.ConfigMap{}
+	}
+	return &corev1.Secret{}
+}
+
+// getStateStore returns the store of a KronosApp of the given kind, or nil
+// when the Secret or ConfigMap it needs does not exist. Only the documents of
+// the selected store are read from the cache, the others being read from the
+// API server so that no informer is started for their kind.
+func (r *KronosAppReconciler) getStateStore(ctx context.Context, stateStore string, kronosApp v1alpha1.KronosAppObject, name, namespace string) (StateStore, error) {
+	if stateStore == StateStoreAnnotations {
+		return newAnnotationStore(r.Client, r.getAPIReader(), getClaimantName(kronosApp)), nil
+	}
+	var reader client.Reader = r.Client
+	if stateStore != r.getSelectedStateStore() {
+		reader = r.getAPIReader()
+	}
+	document := newStateDocument(stateStore, name, namespace)
+	err := reader.Get(ctx, client.ObjectKeyFromObject(document), document)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return newKronosAppDocumentStore(r.Client, document, kronosApp), nil
+}
+
+func newKronosAppDocumentStore(Client client.Client, document client.Object, kronosApp v1alpha1.KronosAppObject) *documentStore {
+	store := newDocumentStore(Client, document)
+	store.kronosApp = getClaimantName(kronosApp)
+	store.generation = kronosApp.GetGeneration()
+	return store
+}
+
+func (r *KronosAppReconciler) createStateDocument(ctx context.Context, name, namespace string, kronosApp v1alpha1.KronosAppObject) (client.Object, error) {
+	document := newStateDocument(r.getSelectedStateStore(), name, namespace)
+	document.SetLabels(map[string]string{
+		"app.kubernetes.io/managed-by": "kronos-core",
+		KronosAppLabel:                 kronosApp.GetName(),
+		KronosAppKindLabel:             getKronosAppKind(kronosApp),
+	})
+	err := controllerutil.SetControllerReference(kronosApp, document, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	setChecksum(document)
+	err = r.Create(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// openStateStore returns the store of a KronosApp selected by the operator,
+// creating its Secret or ConfigMap when missing, and reports whether it was
+// created. Records found in the other stores are migrated to it once per
+// KronosApp after the operator starts, the copy of the original state kept on
+// each resource asleep being used to recover a Secret or ConfigMap deleted
+// meanwhile. The other stores being possibly forbidden to the operator, those
+// it cannot read are left out.
+func (r *KronosAppReconciler) openStateStore(ctx context.Context, kronosApp v1alpha1.KronosAppObject, name, namespace string) (StateStore, bool, error) {
+	l := log.Log
+	created := false
+	store, err := r.getStateStore(ctx, r.getSelectedStateStore(), kronosApp, name, namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	if store == nil {
+		err := checkIfSecretWasCreatedPreviously(kronosApp, name)
+		if err != nil {
+			l.Error(err, "Fetching Secret Records")
+			if IsInArray(kronosApp.GetStatus().CreatedSecrets, name) {
+				r.recordEvent(kronosApp, corev1.EventTypeWarning, ReasonStateStoreMissing, "%s was deleted, recovering the state of the objects asleep from their annotations", name)
+			}
+		}
+		document, err := r.createStateDocument(ctx, name, namespace, kronosApp)
+		if err != nil {
+			return nil, false, err
+		}
+		l.Info("state store created", "store", r.getSelectedStateStore(), "name", name, "namespace", namespace)
+		store = newKronosAppDocumentStore(r.Client, document, kronosApp)
+		created = true
+	}
+
+	if _, migrated := r.migrated.Load(kronosApp.GetUID()); migrated && !created {
+		return store, created, nil
+	}
+	for _, stateStore := range []string{StateStoreSecret, StateStoreConfigMap, StateStoreAnnotations} {
+		if stateStore == r.getSelectedStateStore() || (stateStore == StateStoreAnnotations && !created) {
+			continue
+		}
+		oldStore, err := r.getStateStore(ctx, stateStore, kronosApp, name, namespace)
+		if apierrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if oldStore == nil {
+			continue
+		}
+		migrated, err := migrateState(ctx, oldStore, store)
+		if err != nil {
+			return nil, false, err
+		}
+		if migrated > 0 {
+			l.Info("state migrated", "from", stateStore, "to", r.getSelectedStateStore(), "resources", migrated)
+			r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonStateMigrated, "Migrated the state of %d objects from the %s store to the %s store", migrated, stateStore, r.getSelectedStateStore())
+		}
+		if stateStore != StateStoreAnnotations {
+			err = oldStore.Delete(ctx)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	r.migrated.Store(kronosApp.GetUID(), true)
+	return store, created, nil
+}
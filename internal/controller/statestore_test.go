@@ -0,0 +1,182 @@
+package kronosapp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestDecodeStateDocument(t *testing.T) {
+	record := `[{"name":"web","kind":"Deployment","namespace":"default","replicas":3}]`
+	encoded, err := encodeStateDocument("Deployment", []object.ResourceInt{newTestReplicaResource("web")}, "default/app", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		kind      string
+		data      string
+		expected  []string
+		errSubstr string
+	}{
+		{name: "empty data", kind: "Deployment", data: ""},
+		{name: "blank data", kind: "Deployment", data: " \n"},
+		{name: "bare v1 array", kind: "Deployment", data: record, expected: []string{"web"}},
+		{name: "v2 document", kind: "Deployment", data: `{"version":2,"kind":"Deployment","resources":` + record + `}`, expected: []string{"web"}},
+		{name: "encoded v2 document", kind: "Deployment", data: string(encoded), expected: []string{"web"}},
+		{name: "kind mismatch", kind: "StatefulSet", data: `{"version":2,"kind":"Deployment","resources":` + record + `}`, errSubstr: "holds Deployment records"},
+		{name: "unknown version", kind: "Deployment", data: `{"version":3,"kind":"Deployment","resources":[]}`, errSubstr: "unsupported version 3"},
+		{name: "malformed document", kind: "Deployment", data: `{"version":`, errSubstr: "decoding state of Deployment"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resources, err := decodeStateDocument(test.kind, []byte(test.data))
+			if test.errSubstr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errSubstr) {
+					t.Fatalf("expected an error containing %q, got %v", test.errSubstr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resources) != len(test.expected) {
+				t.Fatalf("expected %d records, got %+v", len(test.expected), resources)
+			}
+			for i, name := range test.expected {
+				if resources[i].GetName() != name || resources[i].GetKind() != test.kind {
+					t.Errorf("expected %s %s, got %s %s", test.kind, name, resources[i].GetKind(), resources[i].GetName())
+				}
+			}
+		})
+	}
+}
+
+func TestAnnotationStoreSelectsOwnerLabel(t *testing.T) {
+	ctx := context.Background()
+	owner := "KronosApp default/app"
+	newDeployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(0))},
+		}
+	}
+	unlabelled := newDeployment("unlabelled")
+	unlabelled.Annotations = map[string]string{
+		object.OwnerAnnotation: owner,
+		object.StateAnnotation: `{"name":"unlabelled","kind":"Deployment","namespace":"default","replicas":3}`,
+	}
+	Client := newTestClient(t, newDeployment("web"), newDeployment("other"), unlabelled)
+	err := setResourceOwner(ctx, Client, newTestReplicaResource("web"), owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = setResourceOwner(ctx, Client, newTestReplicaResource("other"), "KronosApp default/other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := newAnnotationStore(Client, Client, owner).Load(ctx, "Deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 1 || resources[0].GetName() != "web" {
+		t.Fatalf("expected only web to be loaded, got %+v", resources)
+	}
+
+	err = setResourceOwner(ctx, Client, newTestReplicaResource("web"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := &appsv1.Deployment{}
+	err = Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "default"}, web)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := web.Labels[object.OwnerLabel]; ok {
+		t.Errorf("expected the owner label to be removed on release, got %v", web.Labels)
+	}
+}
+
+func TestOpenStateStoreMigration(t *testing.T) {
+	ctx := context.Background()
+	kronosApp := &v1alpha1.KronosApp{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid"}}
+	data, err := encodeStateDocument("Deployment", []object.ResourceInt{newTestReplicaResource("web")}, "KronosApp default/app", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "kronosapp-app", Namespace: "default"},
+		Data:       map[string][]byte{"Deployment": data},
+	}
+	secretReads := 0
+	countSecretReads := func(forbidden bool) interceptor.Funcs {
+		return interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if _, ok := obj.(*corev1.Secret); ok {
+					secretReads++
+					if forbidden {
+						return apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, key.Name, nil)
+					}
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		forbidden bool
+		migrated  int
+	}{
+		{name: "readable secret", migrated: 1},
+		{name: "forbidden secret", forbidden: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			secretReads = 0
+			base := newTestClient(t, kronosApp.DeepCopy(), secret.DeepCopy())
+			r := &KronosAppReconciler{
+				Client:     interceptor.NewClient(base.(client.WithWatch), countSecretReads(true)),
+				Scheme:     base.Scheme(),
+				StateStore: StateStoreConfigMap,
+				APIReader:  interceptor.NewClient(base.(client.WithWatch), countSecretReads(test.forbidden)),
+			}
+			store, created, err := r.openStateStore(ctx, kronosApp, "kronosapp-app", "default")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !created {
+				t.Error("expected the ConfigMap to be created")
+			}
+			resources, err := store.Load(ctx, "Deployment")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resources) != test.migrated {
+				t.Errorf("expected %d records to be migrated, got %d", test.migrated, len(resources))
+			}
+			if secretReads != 1 {
+				t.Errorf("expected the Secret to be read once from the API server, got %d reads", secretReads)
+			}
+			_, _, err = r.openStateStore(ctx, kronosApp, "kronosapp-app", "default")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if secretReads != 1 {
+				t.Errorf("expected the migration to run once, got %d Secret reads", secretReads)
+			}
+		})
+	}
+}
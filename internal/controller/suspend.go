@@ -0,0 +1,96 @@
+package kronosapp
+
+import (
+	"context"
+	"time"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// suspendKronosApp stops applying the schedule of a KronosApp whose
+// spec.suspend is set. With the restore suspend policy, the objects it put to
+// sleep are woken up first; otherwise they are left as they are until the
+// KronosApp is resumed.
+func (r *KronosAppReconciler) suspendKronosApp(ctx context.Context, req ctrl.Request, kronosApp v1alpha1.KronosAppObject, storeName, storeNamespace string) (ctrl.Result, error) {
+	l := log.Log
+	currentStatus := *kronosApp.GetStatus()
+	newStatus := *currentStatus.DeepCopy()
+	newStatus.Status = v1alpha1.StatusSuspended
+	newStatus.Reason = v1alpha1.StatusSuspended
+	newStatus.NextOperation = ""
+	newStatus.NextTransitionTime = nil
+	newStatus.DryRun = r.isDryRun(kronosApp)
+	if currentStatus.Status != v1alpha1.StatusSuspended {
+		r.recordEvent(kronosApp, corev1.EventTypeNormal, ReasonSuspended, "The schedule is suspended")
+	}
+	conditions := []metav1.Condition{
+		newCondition(v1alpha1.ConditionSuspended, metav1.ConditionTrue, "SuspendRequested", "The schedule is not applied while spec.suspend is set"),
+	}
+
+	asleep := meta.IsStatusConditionTrue(currentStatus.Conditions, v1alpha1.ConditionAsleep)
+	t := newTransition(currentStatus.Resources, time.Now())
+	var err error
+	if kronosApp.GetSpec().SuspendPolicy == v1alpha1.SuspendPolicyRestore {
+		var store StateStore
+		store, err = r.getStateStore(ctx, r.getSelectedStateStore(), kronosApp, storeName, storeNamespace)
+		if err == nil && store != nil {
+			if r.isDryRun(kronosApp) {
+				store = newDryRunStore(store)
+			}
+			err = restoreResources(ctx, r.getObjectClient(kronosApp), store, t)
+		}
+		if err != nil {
+			l.Error(err, "Waking Up Resources")
+		}
+		if err == nil && len(t.failed) == 0 {
+			asleep = false
+			conditions = append(conditions, newCondition(v1alpha1.ConditionAsleep, metav1.ConditionFalse, v1alpha1.StatusSuspended, getAsleepMessage(newStatus.DryRun, "The included objects were woken up on suspension")))
+		}
+		if len(t.resources) != 0 {
+			newStatus.Resources, newStatus.ResourcesOmitted = t.getResourceStatuses(&ObjectList{})
+		}
+	}
+
+	switch {
+	case err != nil:
+		conditions = append(conditions,
+			newCondition(v1alpha1.ConditionDegraded, metav1.ConditionTrue, "TransitionFailed", err.Error()),
+			notReady("TransitionFailed", err))
+	case len(t.failed) != 0:
+		failedErr := t.err()
+		conditions = append(conditions,
+			newCondition(v1alpha1.ConditionDegraded, metav1.ConditionTrue, "PartialFailure", failedErr.Error()),
+			notReady("PartialFailure", failedErr))
+	default:
+		conditions = append(conditions,
+			newCondition(v1alpha1.ConditionDegraded, metav1.ConditionFalse, "TransitionSucceeded", "Every included object was handled"),
+			newCondition(v1alpha1.ConditionReady, metav1.ConditionTrue, v1alpha1.StatusSuspended, "The KronosApp is suspended"))
+	}
+	setStatusConditions(&newStatus, kronosApp.GetGeneration(), conditions...)
+	statusErr := v1alpha1.SetNewKronosAppStatus(ctx, r.Client, kronosApp, newStatus)
+	if statusErr != nil {
+		l.Error(statusErr, "Updating KronosApp Status")
+		return ctrl.Result{}, statusErr
+	}
+	if len(t.resources) != 0 {
+		r.recordTransitionEvents(kronosApp, currentStatus, false, t, err)
+	}
+	r.deleteOldMetrics(req, currentStatus)
+	r.exportAdditionalMetrics(req, newStatus, asleep)
+
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(t.failed) != 0 {
+		logFailedObjects(t.failed, l)
+		return ctrl.Result{
+			RequeueAfter: t.getRetryRequeueTime(),
+		}, nil
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,102 @@
+package kronosapp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSuspendKronosApp(t *testing.T) {
+	tests := []struct {
+		name          string
+		suspendPolicy string
+		expected      int32
+	}{
+		{name: "restore", suspendPolicy: v1alpha1.SuspendPolicyRestore, expected: 3},
+		{name: "leave as is", suspendPolicy: v1alpha1.SuspendPolicyLeaveAsIs, expected: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			kronosApp := &v1alpha1.KronosApp{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec: v1alpha1.KronosAppSpec{
+					StartSleep:      "00:00",
+					EndSleep:        "00:00",
+					WeekDays:        "1-5",
+					ForceSleep:      true,
+					SuspendPolicy:   test.suspendPolicy,
+					IncludedObjects: []v1alpha1.IncludedObject{{ApiVersion: "apps/v1", Kind: "Deployment", Namespace: "default"}},
+				},
+			}
+			web := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(3))},
+			}
+			Client := newTestClient(t, kronosApp, web)
+			r := &KronosAppReconciler{Client: Client, Scheme: Client.Scheme()}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+			reconcile := func() {
+				t.Helper()
+				for i := 0; i < 2; i++ {
+					_, err := r.Reconcile(ctx, req)
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+				err := Client.Get(ctx, req.NamespacedName, kronosApp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = Client.Get(ctx, client.ObjectKeyFromObject(web), web)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			reconcile()
+			if *web.Spec.Replicas != 0 {
+				t.Fatalf("expected the Deployment to be put to sleep, got %d replicas", *web.Spec.Replicas)
+			}
+
+			kronosApp.Spec.Suspend = true
+			err := Client.Update(ctx, kronosApp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reconcile()
+			if *web.Spec.Replicas != test.expected {
+				t.Errorf("expected the Deployment to have %d replicas once suspended, got %d", test.expected, *web.Spec.Replicas)
+			}
+			if kronosApp.Status.Status != v1alpha1.StatusSuspended {
+				t.Errorf("expected the status to be %s, got %s", v1alpha1.StatusSuspended, kronosApp.Status.Status)
+			}
+			if !meta.IsStatusConditionTrue(kronosApp.Status.Conditions, v1alpha1.ConditionSuspended) {
+				t.Errorf("expected the Suspended condition to be true, got %+v", kronosApp.Status.Conditions)
+			}
+			if meta.IsStatusConditionTrue(kronosApp.Status.Conditions, v1alpha1.ConditionAsleep) != (test.expected == 0) {
+				t.Errorf("expected the Asleep condition to be %v, got %+v", test.expected == 0, kronosApp.Status.Conditions)
+			}
+
+			kronosApp.Spec.Suspend = false
+			err = Client.Update(ctx, kronosApp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reconcile()
+			if *web.Spec.Replicas != 0 {
+				t.Errorf("expected the Deployment to be put back to sleep once resumed, got %d replicas", *web.Spec.Replicas)
+			}
+			if !meta.IsStatusConditionFalse(kronosApp.Status.Conditions, v1alpha1.ConditionSuspended) {
+				t.Errorf("expected the Suspended condition to be false once resumed, got %+v", kronosApp.Status.Conditions)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package kronosapp
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/KronosOrg/kronos-core/api/v1alpha1"
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// getManagedObjectTypes returns an object of each supported kind served by
+// the cluster, custom resources whose definition is not installed being left
+// out.
+func getManagedObjectTypes(mapper meta.RESTMapper) []client.Object {
+	var objects []client.Object
+	for _, k := range supportedKinds {
+		if k.isCustomResource() {
+			gvk := k.getGroupVersionKind()
+			_, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				continue
+			}
+		}
+		objects = append(objects, k.newObject())
+	}
+	return objects
+}
+
+// managedObjectPredicate lets through the changes made to the spec of the
+// objects of the given kind held asleep by a KronosApp, along with their
+// deletion. The KEDA objects being put to sleep through annotations, which do
+// not bump their generation, changes to their annotations are let through too.
+func managedObjectPredicate(kind string) predicate.Predicate {
+	changed := predicate.Predicate(predicate.GenerationChangedPredicate{})
+	switch kind {
+	case "ScaledObject", "ScaledJob":
+		changed = predicate.Or(changed, predicate.AnnotationChangedPredicate{})
+	}
+	return predicate.And(
+		predicate.NewPredicateFuncs(func(item client.Object) bool {
+			return object.GetOwner(item) != ""
+		}),
+		changed,
+	)
+}
+
+// findOwner returns a function enqueueing the KronosApp of the given kind
+// holding an object asleep, as named by its owner annotation.
+func findOwner(kind string) handler.MapFunc {
+	return func(_ context.Context, item client.Object) []reconcile.Request {
+		owner, found := strings.CutPrefix(object.GetOwner(item), kind+" ")
+		if !found {
+			return nil
+		}
+		namespace, name, found := strings.Cut(owner, "/")
+		if !found {
+			namespace, name = "", owner
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+	}
+}
+
+// newObjectPredicate lets through the objects created or relabelled, which
+// may start matching the included objects of a KronosApp.
+func newObjectPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool {
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return predicate.LabelChangedPredicate{}.Update(e)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// namePatternMatches applies the IncludeRef and ExcludeRef patterns of an
+// included object to an object name, as the fetch functions do.
+func namePatternMatches(includedObject v1alpha1.IncludedObject, name string) bool {
+	if includedObject.IncludeRef == "" && includedObject.ExcludeRef == "" {
+		return true
+	}
+	if includedObject.IncludeRef == includedObject.ExcludeRef {
+		return false
+	}
+	includeRe, err := regexp.Compile(includedObject.IncludeRef)
+	if err != nil {
+		return false
+	}
+	excludeRe, err := regexp.Compile(includedObject.ExcludeRef)
+	if err != nil {
+		return false
+	}
+	return includeRe.MatchString(name) && !excludeRe.MatchString(name)
+}
+
+// includesObject reports whether an included object designates an object of
+// the given kind living in namespace, which is nil when unknown.
+func includesObject(includedObject v1alpha1.IncludedObject, kind string, item metav1.Object, namespace *corev1.Namespace) bool {
+	if includedObject.Kind != "*" && includedObject.Kind != kind {
+		return false
+	}
+	apiVersion := includedObject.ApiVersion
+	if apiVersion == "*" && includedObject.Kind != "*" {
+		// As when fetching, the apiVersion of a given kind is resolved.
+		apiVersion = getSupportedObjectsApiVersionAndKind().GetAPIVersion(kind)
+	}
+	if !IsInArray(getKindsToFetch(apiVersion, includedObject.Kind), kind) {
+		return false
+	}
+	if usesNamespaceSelection(includedObject) {
+		if namespace == nil {
+			return false
+		}
+		matches, err := namespaceMatches(includedObject, namespace)
+		if err != nil || !matches {
+			return false
+		}
+	} else if getNamespace(includedObject) != item.GetNamespace() {
+		return false
+	}
+	if includedObject.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(includedObject.LabelSelector)
+		if err != nil || !selector.Matches(labels.Set(item.GetLabels())) {
+			return false
+		}
+	}
+	return namePatternMatches(includedObject, item.GetName())
+}
+
+// getObjectKindAndNamespace returns the kind of an object along with its
+// namespace, nil when it cannot be fetched.
+func (r *KronosAppReconciler) getObjectKindAndNamespace(ctx context.Context, item client.Object) (string, *corev1.Namespace, error) {
+	gvk, err := apiutil.GVKForObject(item, r.Scheme)
+	if err != nil {
+		return "", nil, err
+	}
+	namespace := &corev1.Namespace{}
+	err = r.Get(ctx, types.NamespacedName{Name: item.GetNamespace()}, namespace)
+	if err != nil {
+		return gvk.Kind, nil, client.IgnoreNotFound(err)
+	}
+	return gvk.Kind, namespace, nil
+}
+
+// findKronosAppsForObject enqueues the KronosApps including an object, so
+// that objects created in a namespace asleep are put to sleep promptly.
+func (r *KronosAppReconciler) findKronosAppsForObject(ctx context.Context, item client.Object) []reconcile.Request {
+	l := log.FromContext(ctx)
+	kind, namespace, err := r.getObjectKindAndNamespace(ctx, item)
+	if err != nil {
+		l.Error(err, "Resolving Object", "name", item.GetName(), "namespace", item.GetNamespace())
+		return nil
+	}
+	kronosApps := &v1alpha1.KronosAppList{}
+	err = r.List(ctx, kronosApps)
+	if err != nil {
+		l.Error(err, "Listing KronosApps")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, kronosApp := range kronosApps.Items {
+		for _, includedObject := range kronosApp.Spec.IncludedObjects {
+			if includesObject(includedObject, kind, item, namespace) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&kronosApp)})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// findClusterKronosAppsForObject enqueues the ClusterKronosApps including an
+// object.
+func (r *ClusterKronosAppReconciler) findClusterKronosAppsForObject(ctx context.Context, item client.Object) []reconcile.Request {
+	l := log.FromContext(ctx)
+	kind, namespace, err := r.getObjectKindAndNamespace(ctx, item)
+	if err != nil {
+		l.Error(err, "Resolving Object", "name", item.GetName(), "namespace", item.GetNamespace())
+		return nil
+	}
+	clusterKronosApps := &v1alpha1.ClusterKronosAppList{}
+	err = r.List(ctx, clusterKronosApps)
+	if err != nil {
+		l.Error(err, "Listing ClusterKronosApps")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, clusterKronosApp := range clusterKronosApps.Items {
+		for _, includedObject := range clusterKronosApp.Spec.IncludedObjects {
+			if includesObject(includedObject, kind, item, namespace) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusterKronosApp)})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// watchManagedObjects watches the objects of every supported kind, enqueueing
+// the KronosApps of the given kind holding them asleep when their spec, or the
+// annotations of the KEDA objects, are changed, and those found by
+// findForObject when they are created or relabelled.
+func watchManagedObjects(b *builder.Builder, mapper meta.RESTMapper, kind string, findForObject handler.MapFunc) *builder.Builder {
+	findRequests := func(ctx context.Context, item client.Object) []reconcile.Request {
+		return append(findOwner(kind)(ctx, item), findForObject(ctx, item)...)
+	}
+	for _, item := range getManagedObjectTypes(mapper) {
+		b = b.Watches(item, handler.EnqueueRequestsFromMapFunc(findRequests), builder.WithPredicates(predicate.Or(managedObjectPredicate(item.GetObjectKind().GroupVersionKind().Kind), newObjectPredicate())))
+	}
+	return b
+}
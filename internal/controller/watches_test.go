@@ -0,0 +1,50 @@
+package kronosapp
+
+import (
+	"testing"
+
+	object "github.com/KronosOrg/kronos-core/internal/controller/included-objects"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestManagedObjectPredicateAnnotations(t *testing.T) {
+	owned := map[string]string{object.OwnerAnnotation: "KronosApp default/app", "autoscaling.keda.sh/paused": "true"}
+	resumed := map[string]string{object.OwnerAnnotation: "KronosApp default/app"}
+
+	scaledObject := func(annotations map[string]string) client.Object {
+		item := &unstructured.Unstructured{}
+		item.SetGroupVersionKind(schema.GroupVersionKind{Group: "keda.sh", Version: "v1alpha1", Kind: "ScaledObject"})
+		item.SetName("web")
+		item.SetAnnotations(annotations)
+		return item
+	}
+	deployment := func(annotations map[string]string) client.Object {
+		item := &appsv1.Deployment{}
+		item.SetName("web")
+		item.SetAnnotations(annotations)
+		return item
+	}
+
+	tests := []struct {
+		name     string
+		kind     string
+		old, new client.Object
+		expected bool
+	}{
+		{name: "KEDA annotations removed", kind: "ScaledObject", old: scaledObject(owned), new: scaledObject(resumed), expected: true},
+		{name: "KEDA annotations unchanged", kind: "ScaledObject", old: scaledObject(owned), new: scaledObject(owned)},
+		{name: "KEDA object not owned", kind: "ScaledObject", old: scaledObject(nil), new: scaledObject(map[string]string{"autoscaling.keda.sh/paused": "true"})},
+		{name: "workload annotations changed", kind: "", old: deployment(owned), new: deployment(resumed)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if managedObjectPredicate(test.kind).Update(event.UpdateEvent{ObjectOld: test.old, ObjectNew: test.new}) != test.expected {
+				t.Errorf("expected the update to be let through: %v", test.expected)
+			}
+		})
+	}
+}